@@ -0,0 +1,131 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type ColumnsStruct struct {
+	ID    int     `xlsx:"id"`
+	Name  string  `xlsx:"name"`
+	Price float64 `xlsx:"price"`
+	Total Formula `xlsx:"total,formula:{price}*2"`
+}
+
+func TestGenerator_Columns(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []GeneratorOption
+		sheet   []SheetOption
+		want    [][]string
+	}{
+		{
+			name:    "selected columns",
+			options: []GeneratorOption{GeneratorOptionColumns([]string{"name", "price", "total"})},
+			want:    [][]string{{"name", "price", "total"}, {"apple", "1.5", "B2*2"}},
+		},
+		{
+			name:    "excluded columns",
+			options: []GeneratorOption{GeneratorOptionExcludeColumns([]string{"id"})},
+			want:    [][]string{{"name", "price", "total"}, {"apple", "1.5", "B2*2"}},
+		},
+		{
+			name:    "renamed headers",
+			options: []GeneratorOption{GeneratorOptionHeaderNames(map[string]string{"id": "ID", "price": "Unit price"})},
+			want:    [][]string{{"ID", "name", "Unit price", "total"}, {"7", "apple", "1.5", "C2*2"}},
+		},
+		{
+			name:    "sheet options",
+			options: []GeneratorOption{GeneratorOptionColumns([]string{"id"}), GeneratorOptionHeaderNames(map[string]string{"id": "ID"})},
+			sheet:   []SheetOption{SheetOptionColumns(nil), SheetOptionExcludeColumns([]string{"total"}), SheetOptionHeaderNames(map[string]string{"name": "Name"})},
+			want:    [][]string{{"ID", "Name", "price"}, {"7", "apple", "1.5"}},
+		},
+	}
+
+	data := []ColumnsStruct{{ID: 7, Name: "apple", Price: 1.5}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.options...)
+			sheetNo, err := generator.AddSheet("sheet", tt.sheet...)
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			if err := generator.AddData(sheetNo, data); err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			var got [][]string
+			for row := range tt.want {
+				var values []string
+				for col := range tt.want[row] {
+					cell, err := sheet.Cell(row, col)
+					if err != nil {
+						t.Fatalf("Cell got err= %v", err)
+					}
+					value := cell.Value
+					if cell.Formula() != "" {
+						value = cell.Formula()
+					}
+					values = append(values, value)
+				}
+				got = append(got, values)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("cells differ from expected (-want +got)\n%s", diff)
+			}
+			if sheet.MaxCol != len(tt.want[0]) {
+				t.Errorf("sheet has %d columns, want %d", sheet.MaxCol, len(tt.want[0]))
+			}
+		})
+	}
+}
+
+func TestUnmarshal_HeaderNames(t *testing.T) {
+	names := map[string]string{"id": "ID", "price": "Unit price"}
+	generator := NewGenerator(GeneratorOptionHeaderNames(names), GeneratorOptionExcludeColumns([]string{"total"}))
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	data := []ColumnsStruct{{ID: 7, Name: "apple", Price: 1.5}, {ID: 8, Name: "pear", Price: 2}}
+	if err := generator.AddData(sheetNo, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	var got []ColumnsStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}),
+		UnmarshalOptionHeaderNames(names))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("sheet differs from expected (-want +got)\n%s", diff)
+	}
+
+	// Conversion errors are reported with header names of the sheet
+	type intPriceRow struct {
+		ID    int `xlsx:"id"`
+		Price int `xlsx:"price"`
+	}
+	var intPrices []intPriceRow
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &intPrices}),
+		UnmarshalOptionHeaderNames(names))
+	var report *ErrUnmarshal
+	if !errors.As(err, &report) || len(report.Cells) == 0 {
+		t.Fatalf("Unmarshal got err= %v, want *ErrUnmarshal", err)
+	}
+	if diff := cmp.Diff("Unit price", report.Cells[0].Header); diff != "" {
+		t.Errorf("Unmarshal report header differs from expected (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,121 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// Commented holds value written with a comment (note) attached to its cell
+type Commented struct {
+	Value interface{}
+	Note  string
+}
+
+// Parts and content types of comments
+const (
+	commentsPartFmt      = "xl/comments%d.xml"
+	vmlDrawingPartFmt    = "xl/drawings/vmlDrawing%d.vml"
+	commentsRelType      = relationshipsNS + "/comments"
+	vmlDrawingRelType    = relationshipsNS + "/vmlDrawing"
+	commentsContentType  = "application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml"
+	vmlDrawingExtension  = `<Default Extension="vml" ContentType="application/vnd.openxmlformats-officedocument.vmlDrawing"/>`
+	commentsAuthor       = "autoxlsx"
+	commentsRelID        = "rIdComments"
+	vmlDrawingRelID      = "rIdVmlDrawing"
+	legacyDrawingElement = `<legacyDrawing r:id="` + vmlDrawingRelID + `"/>`
+)
+
+var commentedType = reflect.TypeFor[Commented]()
+
+// cellComment holds comment of cell at zero based col and row
+type cellComment struct {
+	col  int
+	row  int
+	text string
+}
+
+// isCommentedValue reports whether value is written with comment
+func isCommentedValue(fv reflect.Value) bool {
+	return fv.IsValid() && fv.Type() == commentedType
+}
+
+// setCommentedValue writes Commented value to cell and adds its note to sheet comments
+func (g *Generator) setCommentedValue(sheetNo int, cell *xlsx.Cell, fv reflect.Value) error {
+	commented := fv.Interface().(Commented)
+	if err := addValueToCell(reflect.ValueOf(commented.Value), cell); err != nil {
+		return err
+	}
+
+	if commented.Note != "" {
+		col, row := cell.GetCoordinates()
+		g.addComment(sheetNo, col, g.settings[sheetNo].rowOffset+row, commented.Note)
+	}
+
+	return nil
+}
+
+// addComment adds comment to cell of sheet at zero based col and row
+func (g *Generator) addComment(sheetNo, col, row int, text string) {
+	settings := g.settings[sheetNo]
+	settings.comments = append(settings.comments, cellComment{col: col, row: row, text: text})
+}
+
+// commentsXML returns comments part content
+func commentsXML(comments []cellComment) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	fmt.Fprintf(&b, `<comments xmlns="%s"><authors><author>%s</author></authors><commentList>`, spreadsheetMLNS, commentsAuthor)
+	for _, comment := range comments {
+		fmt.Fprintf(&b, `<comment ref="%s" authorId="0"><text><r><t xml:space="preserve">%s</t></r></text></comment>`,
+			xlsx.GetCellIDStringFromCoords(comment.col, comment.row), escapeXML(comment.text))
+	}
+	b.WriteString("</commentList></comments>")
+
+	return b.String()
+}
+
+// shapesPerBlock is number of shape ids of a 1024 ids block used by comments, id 0 of block is not used
+const shapesPerBlock = 1023
+
+// vmlDrawingXML returns legacy drawing with shapes of comments, Excel does not show comments without it.
+// Shape ids are taken from blocks of 1024 ids starting with block, which no other drawing may use.
+// The next free block is returned.
+func vmlDrawingXML(block int, comments []cellComment) (string, int) {
+	blocks := make([]string, 0, len(comments)/shapesPerBlock+1)
+	for i := 0; i <= (len(comments)-1)/shapesPerBlock; i++ {
+		blocks = append(blocks, strconv.Itoa(block+i))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:x="urn:schemas-microsoft-com:office:excel">`)
+	fmt.Fprintf(&b, `<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="%s"/></o:shapelayout>`, strings.Join(blocks, ","))
+	b.WriteString(`<v:shapetype id="_x0000_t202" coordsize="21600,21600" o:spt="202" path="m,l,21600r21600,l21600,xe">`)
+	b.WriteString(`<v:stroke joinstyle="miter"/><v:path gradientshapeok="t" o:connecttype="rect"/></v:shapetype>`)
+	for i, comment := range comments {
+		fmt.Fprintf(&b, `<v:shape id="_x0000_s%d" type="#_x0000_t202" `, (block+i/shapesPerBlock)*1024+i%shapesPerBlock+1)
+		b.WriteString(`style="position:absolute;margin-left:59.25pt;margin-top:1.5pt;width:108pt;height:59.25pt;z-index:1;visibility:hidden" `)
+		b.WriteString(`fillcolor="#ffffe1" o:insetmode="auto"><v:fill color2="#ffffe1"/><v:shadow on="t" color="black" obscured="t"/>`)
+		b.WriteString(`<v:path o:connecttype="none"/><v:textbox style="mso-direction-alt:auto"><div style="text-align:left"></div></v:textbox>`)
+		fmt.Fprintf(&b, `<x:ClientData ObjectType="Note"><x:MoveWithCells/><x:SizeWithCells/><x:Anchor>%d, 15, %d, 10, %d, 15, %d, 4</x:Anchor>`,
+			comment.col+1, comment.row, comment.col+3, comment.row+4)
+		fmt.Fprintf(&b, `<x:AutoFill>False</x:AutoFill><x:Row>%d</x:Row><x:Column>%d</x:Column></x:ClientData></v:shape>`, comment.row, comment.col)
+	}
+	b.WriteString("</xml>")
+
+	return b.String(), block + len(blocks)
+}
+
+// commentsRelationships returns relationships of sheet to its comments and legacy drawing
+func commentsRelationships(sheetNo int) string {
+	return fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="../comments%d.xml"/>`, commentsRelID, commentsRelType, sheetNo+1) +
+		fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="../drawings/vmlDrawing%d.vml"/>`, vmlDrawingRelID, vmlDrawingRelType, sheetNo+1)
+}
+
+// commentsContentTypeOverride returns content type override of comments part of sheet
+func commentsContentTypeOverride(sheetNo int) string {
+	return fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"/>`, fmt.Sprintf(commentsPartFmt, sheetNo+1), commentsContentType)
+}
@@ -0,0 +1,190 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type CommentStruct struct {
+	ID     int               `xlsx:"id,note:Order number"`
+	Status Commented         `xlsx:"status,desc:Current status"`
+	Extra  map[string]string `xlsx:"extra,note:Extra <data>"`
+}
+
+var commentData = []CommentStruct{
+	{ID: 1, Status: Commented{Value: "open", Note: "waits for payment"}, Extra: map[string]string{"a": "x"}},
+	{ID: 2, Status: Commented{Value: "closed"}},
+}
+
+var wantComments = []string{
+	`<comment ref="A1" authorId="0"><text><r><t xml:space="preserve">Order number</t></r></text></comment>`,
+	`<comment ref="B1" authorId="0"><text><r><t xml:space="preserve">Current status</t></r></text></comment>`,
+	`<comment ref="C1" authorId="0"><text><r><t xml:space="preserve">Extra &lt;data&gt;</t></r></text></comment>`,
+	`<comment ref="B2" authorId="0"><text><r><t xml:space="preserve">waits for payment</t></r></text></comment>`,
+}
+
+// checkCommentParts checks comments of the first sheet of file and parts referencing them
+func checkCommentParts(t *testing.T, file []byte) {
+	t.Helper()
+
+	comments := readPart(t, file, "xl/comments1.xml")
+	for _, comment := range wantComments {
+		if !strings.Contains(comments, comment) {
+			t.Errorf("comments got %s, want %s", comments, comment)
+		}
+	}
+	if got := strings.Count(comments, "<comment "); got != len(wantComments) {
+		t.Errorf("comments count got %d, want %d", got, len(wantComments))
+	}
+	if vml := readPart(t, file, "xl/drawings/vmlDrawing1.vml"); strings.Count(vml, `ObjectType="Note"`) != len(wantComments) {
+		t.Errorf("vml drawing got %s, want %d notes", vml, len(wantComments))
+	}
+	if sheet := readPart(t, file, "xl/worksheets/sheet1.xml"); !strings.Contains(sheet, legacyDrawingElement+"</worksheet>") {
+		t.Errorf("sheet got %s, want legacy drawing", sheet)
+	}
+	if rels := readPart(t, file, "xl/worksheets/_rels/sheet1.xml.rels"); !strings.Contains(rels, commentsRelationships(0)) {
+		t.Errorf("sheet relationships got %s, want comments relationships", rels)
+	}
+	contentTypes := readPart(t, file, "[Content_Types].xml")
+	if !strings.Contains(contentTypes, commentsContentTypeOverride(0)) || strings.Count(contentTypes, vmlDrawingExtension) != 1 {
+		t.Errorf("content types got %s, want comments override and vml extension", contentTypes)
+	}
+}
+
+func TestGenerator_Comments(t *testing.T) {
+	buff := new(bytes.Buffer)
+	if err := Marshal(sheetList.New(map[string]interface{}{"orders": commentData}), buff); err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+	checkCommentParts(t, buff.Bytes())
+
+	buff = new(bytes.Buffer)
+	s := NewStreamGenerator(buff)
+	sheetNo, err := s.AddSheet("orders")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	for _, row := range commentData {
+		if err := s.WriteRows(sheetNo, []CommentStruct{row}); err != nil {
+			t.Fatalf("WriteRows got err= %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+	checkCommentParts(t, buff.Bytes())
+
+	if _, err := xlsx.OpenBinary(buff.Bytes()); err != nil {
+		t.Errorf("OpenBinary got err= %v", err)
+	}
+}
+
+func TestUnmarshal_Commented(t *testing.T) {
+	type commentedRow struct {
+		Status Commented `xlsx:"status"`
+		Count  Commented `xlsx:"count"`
+	}
+
+	buff := new(bytes.Buffer)
+	data := []commentedRow{{Status: Commented{Value: "open", Note: "note"}, Count: Commented{Value: 3}}}
+	if err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff); err != nil {
+		t.Fatalf("unable to prepare workbook, err= %v", err)
+	}
+
+	var got []commentedRow
+	err := Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	want := []commentedRow{{Status: Commented{Value: "open"}, Count: Commented{Value: float64(3)}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+type ManyCommentsStruct struct {
+	Value Commented `xlsx:"value"`
+}
+
+var (
+	idmapPattern   = regexp.MustCompile(`<o:idmap v:ext="edit" data="([0-9,]+)"/>`)
+	shapeIDPattern = regexp.MustCompile(`<v:shape id="_x0000_s([0-9]+)"`)
+)
+
+// checkShapeIDs checks that shape ids of comments of every sheet of file are in blocks of its idmap,
+// which are not used by other sheets
+func checkShapeIDs(t *testing.T, file []byte, sheets int, wantIdmaps []string) {
+	t.Helper()
+
+	usedBlocks := make(map[int]bool)
+	for sheet := 1; sheet <= sheets; sheet++ {
+		vml := readPart(t, file, fmt.Sprintf(vmlDrawingPartFmt, sheet))
+		idmap := idmapPattern.FindStringSubmatch(vml)[1]
+		if diff := cmp.Diff(wantIdmaps[sheet-1], idmap); diff != "" {
+			t.Errorf("idmap of sheet %d differs from expected (-want +got)\n%s", sheet, diff)
+		}
+
+		blocks := make(map[int]bool)
+		for _, block := range strings.Split(idmap, ",") {
+			n, _ := strconv.Atoi(block)
+			if usedBlocks[n] {
+				t.Errorf("block %d of sheet %d is used by another sheet", n, sheet)
+			}
+			blocks[n], usedBlocks[n] = true, true
+		}
+		for _, match := range shapeIDPattern.FindAllStringSubmatch(vml, -1) {
+			id, _ := strconv.Atoi(match[1])
+			if !blocks[id/1024] || id%1024 == 0 {
+				t.Errorf("shape id %d of sheet %d is not in its blocks %s", id, sheet, idmap)
+			}
+		}
+	}
+}
+
+func TestGenerator_ManyComments(t *testing.T) {
+	many := make([]ManyCommentsStruct, 1100)
+	for i := range many {
+		many[i].Value = Commented{Value: i, Note: "note"}
+	}
+	data := sheetList.New(map[string]interface{}{
+		"many": many,
+		"one":  []ManyCommentsStruct{{Value: Commented{Value: 1, Note: "note"}}},
+	}, sheetList.WithSort(true))
+	wantIdmaps := []string{"1,2", "3"}
+
+	t.Run("generator", func(t *testing.T) {
+		buff := new(bytes.Buffer)
+		if err := Marshal(data, buff); err != nil {
+			t.Fatalf("Marshal got err= %v", err)
+		}
+		checkShapeIDs(t, buff.Bytes(), 2, wantIdmaps)
+	})
+
+	t.Run("stream generator", func(t *testing.T) {
+		buff := new(bytes.Buffer)
+		s := NewStreamGenerator(buff)
+		for _, name := range []string{"many", "one"} {
+			sheetNo, err := s.AddSheet(name)
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			values, _ := data.Get()
+			if err := s.WriteRows(sheetNo, values[name]); err != nil {
+				t.Fatalf("WriteRows got err= %v", err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close got err= %v", err)
+		}
+		checkShapeIDs(t, buff.Bytes(), 2, wantIdmaps)
+	})
+}
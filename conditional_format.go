@@ -0,0 +1,214 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arturwwl/gointtoletters"
+)
+
+// ConditionalFormatType holds type of conditional formatting rule
+type ConditionalFormatType string
+
+// Supported conditional formatting rule types
+const (
+	ConditionalFormatCellIs     ConditionalFormatType = "cellIs"
+	ConditionalFormatExpression ConditionalFormatType = "expression"
+	ConditionalFormatBlanks     ConditionalFormatType = "containsBlanks"
+	ConditionalFormatColorScale ConditionalFormatType = "colorScale"
+	ConditionalFormatDataBar    ConditionalFormatType = "dataBar"
+	ConditionalFormatIconSet    ConditionalFormatType = "iconSet"
+)
+
+// cellPlaceholder is replaced in rule formulas with reference of the first data cell of the column
+const cellPlaceholder = "{cell}"
+
+// defaultConditionalStyle is used by rules without style, it's Excel's light red fill with dark red text
+var defaultConditionalStyle = CellStyle{Fill: "FFFFC7CE", FontColor: "FF9C0006"}
+
+// defaultIconSet is used by IconSet rules without icon set
+const defaultIconSet = "3TrafficLights1"
+
+// cellIsOperators maps tag keys to operators of cellIs rule
+var cellIsOperators = map[string]string{
+	"cf-lt":          "lessThan",
+	"cf-le":          "lessThanOrEqual",
+	"cf-eq":          "equal",
+	"cf-ne":          "notEqual",
+	"cf-ge":          "greaterThanOrEqual",
+	"cf-gt":          "greaterThan",
+	"cf-between":     "between",
+	"cf-not-between": "notBetween",
+}
+
+// ConditionalFormat holds conditional formatting rule applied to data cells of a column
+type ConditionalFormat struct {
+	Type     ConditionalFormatType
+	Operator string    // Operator of CellIs rule, e.g. lessThan, between
+	Formulas []string  // Values of CellIs rule or formula of Expression rule, {cell} is replaced with the first data cell
+	Style    CellStyle // Style of CellIs, Expression and Blanks rules, light red is used when empty
+	Colors   []string  // Colors of ColorScale (2 or 3, from lowest value) or DataBar (1) rule
+	IconSet  string    // Icon set of IconSet rule, e.g. 3TrafficLights1
+}
+
+// conditionalColumn holds conditional formatting rules of a column
+type conditionalColumn struct {
+	col   int
+	rules []ConditionalFormat
+}
+
+// parseConditionalFormat parses conditional formatting tag key, false is returned for other keys
+func parseConditionalFormat(key, value string) (ConditionalFormat, bool) {
+	if operator, ok := cellIsOperators[key]; ok {
+		return ConditionalFormat{Type: ConditionalFormatCellIs, Operator: operator, Formulas: strings.Split(value, ":")}, true
+	}
+
+	switch key {
+	case "cf-formula":
+		return ConditionalFormat{Type: ConditionalFormatExpression, Formulas: []string{value}}, true
+	case "cf-blank":
+		return ConditionalFormat{Type: ConditionalFormatBlanks}, true
+	case "cf-scale":
+		return ConditionalFormat{Type: ConditionalFormatColorScale, Colors: strings.Split(value, ":")}, true
+	case "cf-databar":
+		return ConditionalFormat{Type: ConditionalFormatDataBar, Colors: []string{value}}, true
+	case "cf-icons":
+		return ConditionalFormat{Type: ConditionalFormatIconSet, IconSet: value}, true
+	}
+
+	return ConditionalFormat{}, false
+}
+
+// usesStyle reports whether rule formats cells with differential style
+func (cf ConditionalFormat) usesStyle() bool {
+	switch cf.Type {
+	case ConditionalFormatCellIs, ConditionalFormatExpression, ConditionalFormatBlanks:
+		return true
+	}
+
+	return false
+}
+
+// conditionalFormattingXML returns conditionalFormatting elements of columns with data down to lastRow,
+// dxfID returns id of differential style
+func conditionalFormattingXML(columns []conditionalColumn, lastRow int, dxfID func(CellStyle) (int, error)) (string, error) {
+	if lastRow < 2 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	priority := 1
+	for _, column := range columns {
+		letter := gointtoletters.IntToLetters(column.col + 1)
+		firstCell := fmt.Sprintf("%s2", letter)
+
+		fmt.Fprintf(&b, `<conditionalFormatting sqref="%s:%s%d">`, firstCell, letter, lastRow)
+		for _, rule := range column.rules {
+			fmt.Fprintf(&b, `<cfRule type="%s" priority="%d"`, rule.Type, priority)
+			priority++
+
+			if rule.usesStyle() {
+				style := rule.Style
+				if style.IsZero() {
+					style = defaultConditionalStyle
+				}
+				id, err := dxfID(style)
+				if err != nil {
+					return "", err
+				}
+				fmt.Fprintf(&b, ` dxfId="%d"`, id)
+			}
+			if rule.Operator != "" {
+				fmt.Fprintf(&b, ` operator="%s"`, escapeXML(rule.Operator))
+			}
+			b.WriteString(">")
+
+			formulas := rule.Formulas
+			if rule.Type == ConditionalFormatBlanks {
+				formulas = []string{fmt.Sprintf("LEN(TRIM(%s))=0", cellPlaceholder)}
+			}
+			for _, formula := range formulas {
+				formula = strings.TrimPrefix(strings.ReplaceAll(formula, cellPlaceholder, firstCell), "=")
+				fmt.Fprintf(&b, "<formula>%s</formula>", escapeXML(formula))
+			}
+
+			switch rule.Type {
+			case ConditionalFormatColorScale:
+				b.WriteString(`<colorScale><cfvo type="min"/>`)
+				if len(rule.Colors) > 2 {
+					b.WriteString(`<cfvo type="percentile" val="50"/>`)
+				}
+				b.WriteString(`<cfvo type="max"/>`)
+				for _, color := range rule.Colors {
+					fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(color))
+				}
+				b.WriteString("</colorScale>")
+			case ConditionalFormatDataBar:
+				b.WriteString(`<dataBar><cfvo type="min"/><cfvo type="max"/>`)
+				for _, color := range rule.Colors {
+					fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(color))
+				}
+				b.WriteString("</dataBar>")
+			case ConditionalFormatIconSet:
+				iconSet := rule.IconSet
+				if iconSet == "" {
+					iconSet = defaultIconSet
+				}
+				// Icon set names start with the number of icons, e.g. 3Arrows
+				icons, err := strconv.Atoi(iconSet[:1])
+				if err != nil {
+					icons = 3
+				}
+				fmt.Fprintf(&b, `<iconSet iconSet="%s">`, escapeXML(iconSet))
+				for i := 0; i < icons; i++ {
+					fmt.Fprintf(&b, `<cfvo type="percent" val="%d"/>`, i*100/icons)
+				}
+				b.WriteString("</iconSet>")
+			}
+			b.WriteString("</cfRule>")
+		}
+		b.WriteString("</conditionalFormatting>")
+	}
+
+	return b.String(), nil
+}
+
+// dxfXML returns differential style element of style
+func dxfXML(style CellStyle) string {
+	var b strings.Builder
+	b.WriteString("<dxf>")
+	if style.Bold || style.Italic || style.Underline || style.FontColor != "" {
+		b.WriteString("<font>")
+		if style.Bold {
+			b.WriteString("<b/>")
+		}
+		if style.Italic {
+			b.WriteString("<i/>")
+		}
+		if style.Underline {
+			b.WriteString("<u/>")
+		}
+		if style.FontColor != "" {
+			fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(style.FontColor))
+		}
+		b.WriteString("</font>")
+	}
+	if style.Fill != "" {
+		fmt.Fprintf(&b, `<fill><patternFill patternType="solid"><bgColor rgb="%s"/></patternFill></fill>`, escapeXML(style.Fill))
+	}
+	if style.Border != "" {
+		b.WriteString("<border>")
+		for _, side := range []string{"left", "right", "top", "bottom"} {
+			fmt.Fprintf(&b, `<%s style="%s">`, side, escapeXML(style.Border))
+			if style.BorderColor != "" {
+				fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(style.BorderColor))
+			}
+			fmt.Fprintf(&b, "</%s>", side)
+		}
+		b.WriteString("</border>")
+	}
+	b.WriteString("</dxf>")
+
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package autoxlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+)
+
+type ConditionalStruct struct {
+	ID     int     `xlsx:"id,cf-blank"`
+	Amount float64 `xlsx:"amount,cf-lt:0,cf-font-color:FFFF0000"`
+	Score  int     `xlsx:"score,cf-scale:FFF8696B:FF63BE7B,cf-icons:3Arrows"`
+}
+
+// readPart returns content of part of xlsx file
+func readPart(t *testing.T, file []byte, name string) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("zip.NewReader got err= %v", err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("Open(%s) got err= %v", name, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll(%s) got err= %v", name, err)
+	}
+
+	return string(content)
+}
+
+func TestConditionalFormattingXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []conditionalColumn
+		lastRow int
+		want    string
+	}{
+		{
+			name:    "no data rows",
+			columns: []conditionalColumn{{col: 0, rules: []ConditionalFormat{{Type: ConditionalFormatBlanks}}}},
+			lastRow: 1,
+			want:    "",
+		},
+		{
+			name: "cell value and expression",
+			columns: []conditionalColumn{{col: 1, rules: []ConditionalFormat{
+				{Type: ConditionalFormatCellIs, Operator: "between", Formulas: []string{"1", "10"}},
+				{Type: ConditionalFormatExpression, Formulas: []string{"={cell}<TODAY()"}, Style: CellStyle{Fill: "FFFFFF00"}},
+			}}},
+			lastRow: 5,
+			want: `<conditionalFormatting sqref="B2:B5">` +
+				`<cfRule type="cellIs" priority="1" dxfId="0" operator="between"><formula>1</formula><formula>10</formula></cfRule>` +
+				`<cfRule type="expression" priority="2" dxfId="1"><formula>B2&lt;TODAY()</formula></cfRule>` +
+				`</conditionalFormatting>`,
+		},
+		{
+			name: "data bar and icon set",
+			columns: []conditionalColumn{{col: 0, rules: []ConditionalFormat{
+				{Type: ConditionalFormatDataBar, Colors: []string{"FF638EC6"}},
+				{Type: ConditionalFormatIconSet},
+			}}},
+			lastRow: 3,
+			want: `<conditionalFormatting sqref="A2:A3">` +
+				`<cfRule type="dataBar" priority="1"><dataBar><cfvo type="min"/><cfvo type="max"/><color rgb="FF638EC6"/></dataBar></cfRule>` +
+				`<cfRule type="iconSet" priority="2"><iconSet iconSet="3TrafficLights1"><cfvo type="percent" val="0"/>` +
+				`<cfvo type="percent" val="33"/><cfvo type="percent" val="66"/></iconSet></cfRule>` +
+				`</conditionalFormatting>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dxfs styleTable
+			got, err := conditionalFormattingXML(tt.columns, tt.lastRow, NewGenerator().dxfID(&dxfs))
+			if err != nil {
+				t.Fatalf("conditionalFormattingXML got err= %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("conditionalFormattingXML return value differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerator_ConditionalFormat(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionConditionalFormat(map[string][]ConditionalFormat{
+		"id": {{Type: ConditionalFormatCellIs, Operator: "greaterThan", Formulas: []string{"100"}}},
+	}))
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	err = generator.AddData(sheetNo, []ConditionalStruct{{ID: 1, Amount: -2, Score: 3}, {ID: 2, Amount: 5, Score: 1}})
+	if err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	sheet := readPart(t, buff.Bytes(), "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<conditionalFormatting sqref="A2:A3"><cfRule type="containsBlanks" priority="1" dxfId="0"><formula>LEN(TRIM(A2))=0</formula></cfRule>` +
+			`<cfRule type="cellIs" priority="2" dxfId="0" operator="greaterThan"><formula>100</formula></cfRule></conditionalFormatting>`,
+		`<conditionalFormatting sqref="B2:B3"><cfRule type="cellIs" priority="3" dxfId="1" operator="lessThan">`,
+		`<conditionalFormatting sqref="C2:C3"><cfRule type="colorScale" priority="4">`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("worksheet does not contain %s", want)
+		}
+	}
+
+	styles := readPart(t, buff.Bytes(), "xl/styles.xml")
+	if !strings.Contains(styles, `<dxfs count="2"><dxf><font><color rgb="FF9C0006"/></font>`) {
+		t.Errorf("styles do not contain differential styles, got %s", styles)
+	}
+
+	if _, err := xlsx.OpenBinary(buff.Bytes()); err != nil {
+		t.Errorf("OpenBinary got err= %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package autoxlsx
+
+import "reflect"
+
+// DropdownValuer is implemented by enum types, XLSXValues are used as dropdown values of fields of the type
+// tagged with dropdown:, unless GeneratorOptionCustomDropdown gives values of the column
+type DropdownValuer interface {
+	XLSXValues() []string
+}
+
+var dropdownValuerType = reflect.TypeFor[DropdownValuer]()
+
+// enumDropdownValues returns XLSXValues of t, which can be implemented by t or pointer to it.
+// Pointer and map types use their element type.
+func enumDropdownValues(t reflect.Type) ([]string, bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	// Method is called on pointer to zero value, so it works for both value and pointer receivers
+	if reflect.PointerTo(t).Implements(dropdownValuerType) {
+		return reflect.New(t).Interface().(DropdownValuer).XLSXValues(), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,52 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type Status string
+
+func (Status) XLSXValues() []string {
+	return []string{"new", "paid", "sent"}
+}
+
+type Priority int
+
+func (*Priority) XLSXValues() []string {
+	return []string{"low", "high"}
+}
+
+type EnumStruct struct {
+	Status   Status            `xlsx:"status,dropdown:5"`
+	Previous *Status           `xlsx:"previous,dropdown:5"`
+	Priority Priority          `xlsx:"priority,dropdown:5"`
+	Override Status            `xlsx:"override,dropdown:5"`
+	History  map[string]Status `xlsx:"*,dropdown:5"`
+	Plain    Status            `xlsx:"plain"`
+}
+
+func TestGenerator_EnumDropdown(t *testing.T) {
+	data := []EnumStruct{{Status: "new", History: map[string]Status{"2024": "paid"}}}
+	buff := new(bytes.Buffer)
+	err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff,
+		GeneratorOptionCustomDropdown(map[string][]string{"override": {"open", "closed"}}))
+	if err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+
+	want := []string{
+		`&#34;new,paid,sent&#34;`,
+		`&#34;new,paid,sent&#34;`,
+		`&#34;low,high&#34;`,
+		`&#34;open,closed&#34;`,
+		`&#34;new,paid,sent&#34;`,
+	}
+	if diff := cmp.Diff(want, validationFormulas(t, buff.Bytes())); diff != "" {
+		t.Errorf("dropdown formulas differ from expected (-want +got)\n%s", diff)
+	}
+}
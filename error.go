@@ -1,13 +1,19 @@
 package autoxlsx
 
-// ErrExpectedSlice is returned when a function expects a slice but receives a different data type.
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrExpectedSlice is returned when a function expects a slice (or other data source) but receives a different data type.
 type ErrExpectedSlice struct{}
 
 func (e *ErrExpectedSlice) Error() string {
-	return "expected a slice, got a different data type"
+	return "expected a slice, iterator or channel, got a different data type"
 }
 
-// ErrEmptySlice is returned when a provided slice is empty.
+// ErrEmptySlice is returned when a provided slice (or other data source) is empty.
 type ErrEmptySlice struct{}
 
 func (e *ErrEmptySlice) Error() string {
@@ -21,9 +27,149 @@ func (e *ErrSheetNotFound) Error() string {
 	return "specified sheet not found"
 }
 
-// ErrInconsistentMapKeys is returned when all entities must have consistent keys for map fields.
+// ErrInconsistentMapKeys is returned when map keys of an entity have no columns, which happens when
+// StreamGenerator gets new keys after headers were written.
 type ErrInconsistentMapKeys struct{}
 
 func (e *ErrInconsistentMapKeys) Error() string {
 	return "all entities must have consistent keys for map fields"
 }
+
+// ErrInvalidMapKey is returned for map field with columns which key type is not string.
+type ErrInvalidMapKey struct {
+	Field string
+	Key   reflect.Type
+}
+
+func (e *ErrInvalidMapKey) Error() string {
+	return fmt.Sprintf("field %s: map key has to be a string, got %s", e.Field, e.Key)
+}
+
+// ErrUnassignedColumns is returned by Unmarshal when header columns cannot be assigned to map fields,
+// which happens when the sheet layout differs from the one of AddTableHeaders.
+type ErrUnassignedColumns struct {
+	Headers []string
+}
+
+func (e *ErrUnassignedColumns) Error() string {
+	return fmt.Sprintf("columns %q cannot be assigned to map fields", e.Headers)
+}
+
+// ErrStyleNotFound is returned when a tag references a style which is not defined in StyleRegistry.
+type ErrStyleNotFound struct {
+	Name string
+}
+
+func (e *ErrStyleNotFound) Error() string {
+	return fmt.Sprintf("style %q is not defined", e.Name)
+}
+
+// ErrFormulaColumnNotFound is returned when a formula placeholder references a column which is not in headers.
+type ErrFormulaColumnNotFound struct {
+	Name string
+}
+
+func (e *ErrFormulaColumnNotFound) Error() string {
+	return fmt.Sprintf("formula references column %q which is not in headers", e.Name)
+}
+
+// ErrUnknownTotal is returned when total: tag holds an unsupported aggregate.
+type ErrUnknownTotal struct {
+	Name string
+}
+
+func (e *ErrUnknownTotal) Error() string {
+	return fmt.Sprintf("unknown total %q, expected sum, avg, count, min or max", e.Name)
+}
+
+// ErrDropdownParentNotFound is returned when dropdown-depends: tag references a column which is not on its left.
+type ErrDropdownParentNotFound struct {
+	Name string
+}
+
+func (e *ErrDropdownParentNotFound) Error() string {
+	return fmt.Sprintf("dependent dropdown references column %q which is not on its left", e.Name)
+}
+
+// ErrInvalidValidation is returned when a data validation tag key holds an invalid value.
+type ErrInvalidValidation struct {
+	Key   string
+	Value string
+}
+
+func (e *ErrInvalidValidation) Error() string {
+	return fmt.Sprintf("invalid data validation %s:%s", e.Key, e.Value)
+}
+
+// ErrExpectedSlicePointer is returned when a function expects a pointer to slice of structs but receives a different data type.
+type ErrExpectedSlicePointer struct{}
+
+func (e *ErrExpectedSlicePointer) Error() string {
+	return "expected a pointer to slice of structs, got a different data type"
+}
+
+// ErrCellConversion is returned when a cell value cannot be converted to the type of its target field.
+type ErrCellConversion struct {
+	Sheet  string       // Name of the sheet
+	Row    int          // Row number, starting from 1
+	Column string       // Column letter
+	Header string       // Header of the column
+	Value  string       // Raw cell text
+	Type   reflect.Type // Type of the target field
+	Err    error        // Underlying conversion error
+}
+
+func (e *ErrCellConversion) Error() string {
+	return fmt.Sprintf("sheet %q, cell %s%d (%s): cannot convert %q to %s: %v", e.Sheet, e.Column, e.Row, e.Header, e.Value, e.Type, e.Err)
+}
+
+func (e *ErrCellConversion) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnmarshal is returned when one or more cells cannot be converted, it holds every failed cell.
+type ErrUnmarshal struct {
+	Cells []*ErrCellConversion
+}
+
+func (e *ErrUnmarshal) Error() string {
+	if len(e.Cells) == 1 {
+		return e.Cells[0].Error()
+	}
+
+	return fmt.Sprintf("%d cells could not be converted, first: %v", len(e.Cells), e.Cells[0])
+}
+
+func (e *ErrUnmarshal) Unwrap() []error {
+	errs := make([]error, 0, len(e.Cells))
+	for _, cell := range e.Cells {
+		errs = append(errs, cell)
+	}
+
+	return errs
+}
+
+// appendCellErrors merges cell errors of err into report, other errors are returned as they are
+func appendCellErrors(report *ErrUnmarshal, err error) error {
+	var unmarshalErr *ErrUnmarshal
+	if !errors.As(err, &unmarshalErr) {
+		return err
+	}
+	report.Cells = append(report.Cells, unmarshalErr.Cells...)
+
+	return nil
+}
+
+// ErrSheetFinished is returned when rows are written to a sheet which was already finished by StreamGenerator.
+type ErrSheetFinished struct{}
+
+func (e *ErrSheetFinished) Error() string {
+	return "sheet was already finished, no more rows can be written to it"
+}
+
+// ErrStreamClosed is returned when StreamGenerator is used after it was closed.
+type ErrStreamClosed struct{}
+
+func (e *ErrStreamClosed) Error() string {
+	return "stream generator is already closed"
+}
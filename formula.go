@@ -0,0 +1,59 @@
+package autoxlsx
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arturwwl/gointtoletters"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// Formula holds formula written to cell instead of value. Like formula: tag option it can contain placeholders:
+// {row} is replaced with number of the current row and {name} with reference of the current row's cell
+// in column with header name, e.g. "={price}*{quantity}" or "=C{row}-D{row}".
+type Formula string
+
+// rowPlaceholder is replaced in formulas with number of the current row
+const rowPlaceholder = "row"
+
+var formulaType = reflect.TypeFor[Formula]()
+
+var formulaPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// resolveFormula replaces placeholders of formula written to row
+func (g *Generator) resolveFormula(sheetNo int, formula string, row *xlsx.Row) (string, error) {
+	settings := g.settings[sheetNo]
+	rowNo := strconv.Itoa(settings.rowOffset + row.GetCoordinate() + 1)
+
+	var err error
+	formula = formulaPlaceholder.ReplaceAllStringFunc(formula, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if name == rowPlaceholder {
+			return rowNo
+		}
+
+		col, ok := settings.columns[name]
+		if !ok {
+			err = &ErrFormulaColumnNotFound{Name: name}
+			return placeholder
+		}
+
+		return gointtoletters.IntToLetters(col+1) + rowNo
+	})
+
+	return strings.TrimPrefix(formula, "="), err
+}
+
+// setCellFormula writes resolved formula to cell
+func (g *Generator) setCellFormula(sheetNo int, cell *xlsx.Cell, formula string) error {
+	formula, err := g.resolveFormula(sheetNo, formula, cell.Row)
+	if err != nil {
+		return err
+	}
+
+	cell.SetFormula(formula)
+
+	return nil
+}
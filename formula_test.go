@@ -0,0 +1,126 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+)
+
+type RoundStruct struct {
+	Price   float64 `xlsx:"price"`
+	Rounded float64 `xlsx:"rounded,formula:=ROUND({price}*2,2)"`
+}
+
+type FormulaStruct struct {
+	Price    float64 `xlsx:"price"`
+	Quantity int     `xlsx:"quantity"`
+	Total    float64 `xlsx:"total,formula:={price}*{quantity}"`
+	Margin   Formula `xlsx:"margin"`
+}
+
+// sheetFormulas returns formulas of data rows of sheet
+func sheetFormulas(t *testing.T, sheet *xlsx.Sheet) [][]string {
+	t.Helper()
+
+	var got [][]string
+	err := sheet.ForEachRow(func(row *xlsx.Row) error {
+		if row.GetCoordinate() == 0 {
+			return nil
+		}
+		var formulas []string
+		err := row.ForEachCell(func(cell *xlsx.Cell) error {
+			formulas = append(formulas, cell.Formula())
+			return nil
+		})
+		got = append(got, formulas)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ForEachRow got err= %v", err)
+	}
+
+	return got
+}
+
+func TestGenerator_Formula(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "tag and value formulas",
+			data: []FormulaStruct{
+				{Price: 2, Quantity: 3, Margin: "=C{row}-A{row}"},
+				{Price: 4, Quantity: 5, Margin: "={total}/{price}"},
+			},
+			want: [][]string{
+				{"", "", "A2*B2", "C2-A2"},
+				{"", "", "A3*B3", "C3/A3"},
+			},
+		},
+		{
+			name: "formula with arguments",
+			data: []RoundStruct{{Price: 1.234}},
+			want: [][]string{{"", "ROUND(A2*2,2)"}},
+		},
+		{
+			name:    "unknown column",
+			data:    []FormulaStruct{{Margin: "={cost}"}},
+			wantErr: &ErrFormulaColumnNotFound{Name: "cost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator()
+			sheetNo, err := generator.AddSheet("sheet")
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+
+			err = generator.AddData(sheetNo, tt.data)
+			if diff := cmp.Diff(tt.wantErr, err, cmp.Comparer(func(a, b error) bool { return errors.Is(a, b) || a.Error() == b.Error() })); diff != "" {
+				t.Fatalf("AddData error differs from expected (-want +got)\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			if diff := cmp.Diff(tt.want, sheetFormulas(t, sheet)); diff != "" {
+				t.Errorf("formulas differ from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStreamGenerator_Formula(t *testing.T) {
+	buff := new(bytes.Buffer)
+	s := NewStreamGenerator(buff)
+	sheetNo, err := s.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	for _, batch := range [][]FormulaStruct{{{Price: 1}, {Price: 2}}, {{Price: 3}}} {
+		if err := s.WriteRows(sheetNo, batch); err != nil {
+			t.Fatalf("WriteRows got err= %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	want := [][]string{{"", "", "A2*B2", ""}, {"", "", "A3*B3", ""}, {"", "", "A4*B4", ""}}
+	if diff := cmp.Diff(want, sheetFormulas(t, wb.Sheet["sheet"])); diff != "" {
+		t.Errorf("formulas differ from expected (-want +got)\n%s", diff)
+	}
+}
@@ -3,6 +3,7 @@ package autoxlsx
 import (
 	"fmt"
 	"io"
+	"maps"
 	"reflect"
 	"slices"
 	"sync"
@@ -19,12 +20,61 @@ type Generator struct {
 	sync.Mutex
 	sheets            []*xlsx.Sheet
 	customOptions     [][]*CustomOptions
+	mapKeys           []map[helpers.MapField][]string
+	settings          []*sheetSettings
 	wb                *xlsx.File
 	autoFilter        bool
 	freezeFirstColumn bool
 	freezeFirstRow    bool
+	freezePanes       *GeneratorOptionFreezePanes
+	freezeAuto        bool
+	emptyTemplate     bool
 	customDropdown    map[string][]string
+	dependentDropdown map[string]map[string][]string
+	labeledDropdown   map[string][]DropdownItem
 	hiddenSheets      []string
+	styles            *StyleRegistry
+	conditionalFormat map[string][]ConditionalFormat
+	totalRow          GeneratorOptionTotalRow
+	sanitizeStrings   bool
+	columnOrder       []string
+	includeColumns    []string
+	excludeColumns    []string
+	headerNames       map[string]string
+	lookup            *lookupSheet
+}
+
+// sheetSettings holds options of a single sheet, resolved from generator options and sheet options
+type sheetSettings struct {
+	autoFilter     bool
+	freezeRows     int
+	freezeCols     int
+	freezeAuto     bool
+	freezeKeyCols  int
+	hidden         bool
+	veryHidden     bool
+	sanitize       bool
+	customDropdown map[string][]string
+	// dependentDropdown holds lists by column name and value of parent column
+	dependentDropdown map[string]map[string][]string
+	labeledDropdown   map[string][]DropdownItem
+	// conditionalFormat holds rules by column name, conditionalColumns rules of written columns
+	conditionalFormat  map[string][]ConditionalFormat
+	conditionalColumns []conditionalColumn
+	lastRow            int
+	// columns holds column indexes by header name, rowOffset number of rows written before current sheet rows
+	columns   map[string]int
+	rowOffset int
+	totalRow  GeneratorOptionTotalRow
+	totals    []totalColumn
+	comments  []cellComment
+	// columnOrder holds header names placed first, layout holds columns by index of custom options (-1 when skipped)
+	columnOrder []string
+	layout      []int
+	// includeColumns and excludeColumns select columns by tag name, headerNames holds headers by tag name
+	includeColumns []string
+	excludeColumns []string
+	headerNames    map[string]string
 }
 
 // NewGenerator creates new generator instance
@@ -34,6 +84,7 @@ func NewGenerator(options ...GeneratorOption) *Generator {
 		sheets:        nil,
 		customOptions: nil,
 		wb:            xlsx.NewFile(),
+		styles:        NewStyleRegistry(),
 	}
 
 	for _, option := range options {
@@ -44,21 +95,57 @@ func NewGenerator(options ...GeneratorOption) *Generator {
 			g.freezeFirstColumn = true
 		case GeneratorOptionFreezeFirstRow:
 			g.freezeFirstRow = true
+		case GeneratorOptionFreezePanes:
+			g.freezePanes = &v
+		case GeneratorOptionFreezeAuto:
+			g.freezeAuto = true
+		case GeneratorOptionEmptyTemplate:
+			g.emptyTemplate = true
 		case generatorOptionCustomDropdown:
 			g.customDropdown = v.values
+		case generatorOptionDependentDropdown:
+			g.dependentDropdown = v.values
+		case generatorOptionLabeledDropdown:
+			g.labeledDropdown = v.values
 		case generatorOptionHiddenSheets:
 			g.hiddenSheets = v.values
+		case generatorOptionStyles:
+			g.styles = v.registry
+		case generatorOptionConditionalFormat:
+			g.conditionalFormat = v.values
+		case GeneratorOptionTotalRow:
+			g.totalRow = v
+		case GeneratorOptionSanitizeStrings, GeneratorOptionSafeMode:
+			g.sanitizeStrings = true
+		case generatorOptionColumnOrder:
+			g.columnOrder = v.names
+		case generatorOptionColumns:
+			g.includeColumns = v.names
+		case generatorOptionExcludeColumns:
+			g.excludeColumns = v.names
+		case generatorOptionHeaderNames:
+			g.headerNames = v.values
 		}
 	}
 
 	return g
 }
 
+// Styles returns style registry of generator, named styles have to be defined before data is added
+func (g *Generator) Styles() *StyleRegistry {
+	return g.styles
+}
+
 // GenerateXLSX generate xlsx for provided slice
 func (g *Generator) GenerateXLSX(list *sheetList.List) error {
 	data, keys := list.Get()
 	for _, sheetName := range keys {
-		sheetNo, err := g.AddSheet(sheetName)
+		var options []SheetOption
+		for _, option := range list.SheetOptions(sheetName) {
+			options = append(options, option)
+		}
+
+		sheetNo, err := g.AddSheet(sheetName, options...)
 		if err != nil {
 			return err
 		}
@@ -72,25 +159,121 @@ func (g *Generator) GenerateXLSX(list *sheetList.List) error {
 	return nil
 }
 
-// AddSheet creates new sheet
-func (g *Generator) AddSheet(sheetName string) (int, error) {
+// AddSheet creates new sheet, sheet options override generator options for this sheet only
+func (g *Generator) AddSheet(sheetName string, options ...SheetOption) (int, error) {
 	sheet, err := g.wb.AddSheet(sheetName)
 	if err != nil {
 		return -1, err
 	}
 
-	if slices.Contains(g.hiddenSheets, sheetName) {
-		sheet.Hidden = true
-	}
+	settings := g.newSheetSettings(sheetName, options)
+	sheet.Hidden = settings.hidden
 
 	g.Mutex.Lock()
 	defer g.Mutex.Unlock()
+
 	g.sheets = append(g.sheets, sheet)
 	g.customOptions = append(g.customOptions, []*CustomOptions{})
+	g.mapKeys = append(g.mapKeys, make(map[helpers.MapField][]string))
+	g.settings = append(g.settings, settings)
 
 	return len(g.sheets) - 1, nil
 }
 
+// newSheetSettings resolves settings of a sheet, generator options are used as defaults
+func (g *Generator) newSheetSettings(sheetName string, options []SheetOption) *sheetSettings {
+	settings := &sheetSettings{
+		autoFilter:     g.autoFilter,
+		freezeAuto:     g.freezeAuto,
+		hidden:         slices.Contains(g.hiddenSheets, sheetName),
+		customDropdown: g.customDropdown,
+		sanitize:       g.sanitizeStrings,
+
+		dependentDropdown: g.dependentDropdown,
+		labeledDropdown:   g.labeledDropdown,
+
+		conditionalFormat: g.conditionalFormat,
+		totalRow:          g.totalRow,
+		columnOrder:       g.columnOrder,
+		includeColumns:    g.includeColumns,
+		excludeColumns:    g.excludeColumns,
+		headerNames:       g.headerNames,
+	}
+	if g.freezeFirstRow {
+		settings.freezeRows = 1
+	}
+	if g.freezeFirstColumn {
+		settings.freezeCols = 1
+	}
+	if g.freezePanes != nil {
+		settings.freezeRows, settings.freezeCols = g.freezePanes.Rows, g.freezePanes.Cols
+	}
+
+	for _, option := range options {
+		switch v := option.(type) {
+		case sheetOptionAutoFilter:
+			settings.autoFilter = v.enabled
+		case sheetOptionSanitizeStrings:
+			settings.sanitize = v.enabled
+		case sheetOptionFreezeFirstColumn:
+			settings.freezeCols = boolToInt(v.enabled)
+		case sheetOptionFreezeFirstRow:
+			settings.freezeRows = boolToInt(v.enabled)
+		case sheetOptionFreezePanes:
+			settings.freezeRows, settings.freezeCols = v.rows, v.cols
+		case sheetOptionFreezeAuto:
+			settings.freezeAuto = v.enabled
+		case sheetOptionTotalRow:
+			settings.totalRow = v.totalRow
+		case sheetOptionHidden:
+			settings.hidden = v.enabled
+		case sheetOptionColumnOrder:
+			settings.columnOrder = v.names
+		case sheetOptionColumns:
+			settings.includeColumns = v.names
+		case sheetOptionExcludeColumns:
+			settings.excludeColumns = v.names
+		case sheetOptionHeaderNames:
+			headerNames := maps.Clone(settings.headerNames)
+			if headerNames == nil {
+				headerNames = make(map[string]string)
+			}
+			maps.Copy(headerNames, v.values)
+			settings.headerNames = headerNames
+		case sheetOptionCustomDropdown:
+			customDropdown := maps.Clone(settings.customDropdown)
+			if customDropdown == nil {
+				customDropdown = make(map[string][]string)
+			}
+			maps.Copy(customDropdown, v.values)
+			settings.customDropdown = customDropdown
+		case sheetOptionDependentDropdown:
+			dependentDropdown := maps.Clone(settings.dependentDropdown)
+			if dependentDropdown == nil {
+				dependentDropdown = make(map[string]map[string][]string)
+			}
+			maps.Copy(dependentDropdown, v.values)
+			settings.dependentDropdown = dependentDropdown
+		case sheetOptionLabeledDropdown:
+			labeledDropdown := maps.Clone(settings.labeledDropdown)
+			if labeledDropdown == nil {
+				labeledDropdown = make(map[string][]DropdownItem)
+			}
+			maps.Copy(labeledDropdown, v.values)
+			settings.labeledDropdown = labeledDropdown
+		case sheetOptionConditionalFormat:
+			conditionalFormat := maps.Clone(settings.conditionalFormat)
+			if conditionalFormat == nil {
+				conditionalFormat = make(map[string][]ConditionalFormat)
+			}
+			maps.Copy(conditionalFormat, v.values)
+			settings.conditionalFormat = conditionalFormat
+		}
+	}
+
+	return settings
+}
+
 // GetSheet if not found it returns an error
 func (g *Generator) GetSheet(sheetNo int) (*xlsx.Sheet, error) {
 	if len(g.sheets) <= sheetNo {
@@ -100,54 +283,44 @@ func (g *Generator) GetSheet(sheetNo int) (*xlsx.Sheet, error) {
 	return g.sheets[sheetNo], nil
 }
 
-// validateAndLength validates the input data, returns the slice length, and an error if validation fails
-func validateAndLength(data interface{}) (int, error) {
-	sliceValue := reflect.ValueOf(data)
-	if sliceValue.Kind() != reflect.Slice {
-		return 0, &ErrExpectedSlice{}
+// validateSource validates the input data, it returns an error if data is not a slice, iterator or channel of structs
+func validateSource(data interface{}) error {
+	if sourceItemType(data) == nil {
+		return &ErrExpectedSlice{}
+	}
+
+	return nil
+}
+
+// sourceItemType returns struct type of data source items, nil is returned for unsupported data
+func sourceItemType(data interface{}) reflect.Type {
+	itemType, ok := helpers.SourceElemType(reflect.TypeOf(data))
+	if !ok {
+		return nil
 	}
 
-	sliceLen := sliceValue.Len()
-	if sliceLen == 0 {
-		return 0, &ErrEmptySlice{}
+	if itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		return nil
 	}
 
-	return sliceLen, nil
+	return itemType
 }
 
-// processHeaders processes headers for the given item and updates mapFields if needed
-func (g *Generator) processHeaders(sheetNo int, itemType reflect.Type, itemValue reflect.Value, mapFields *[]string) (int, error) {
-	count, withMap, err := g.AddTableHeaders(nil, sheetNo, itemType, itemValue, 0)
+// processHeaders processes headers for the given item type
+func (g *Generator) processHeaders(sheetNo int, itemType reflect.Type) (int, error) {
+	count, _, err := g.AddTableHeaders(nil, sheetNo, itemType, reflect.Value{}, 0)
 	if err != nil {
 		return 0, err
 	}
 
-	// Identify fields with map type
-	if withMap {
-		for j := 0; j < itemType.NumField(); j++ {
-			field := itemType.Field(j)
-			if field.Type.Kind() == reflect.Map {
-				*mapFields = append(*mapFields, field.Name)
-			}
-		}
-	}
-
 	return count, nil
 }
 
-// processItem processes an individual item, updating mapValues and processing data cells
-func (g *Generator) processItem(sheetNo int, itemType reflect.Type, itemValue reflect.Value, mapFields []string, mapValues map[string][]reflect.Value) error {
-	// Collect map values for comparison
-	for _, fieldName := range mapFields {
-		field, found := itemType.FieldByName(fieldName)
-		if !found {
-			return fmt.Errorf("field %s not found in type %s", fieldName, itemType.Name())
-		}
-		index := field.Index[0] // Assuming map fields have only one index
-		mapValues[fieldName] = append(mapValues[fieldName], itemValue.Field(index))
-	}
-
-	// Process data cells
+// processItem processes an individual item, adding its data cells
+func (g *Generator) processItem(sheetNo int, itemType reflect.Type, itemValue reflect.Value) error {
 	_, err := g.AddTableDataCells(nil, sheetNo, itemType, itemValue, 0)
 	return err
 }
@@ -159,104 +332,238 @@ func (g *Generator) setSheetProperties(sheetNo, rowLength, sliceLen int) error {
 		return err
 	}
 
-	if g.autoFilter {
+	g.setAutoFilter(sheetNo, sheet, rowLength, sliceLen)
+	g.setSheetViews(sheetNo, sheet)
+	g.settings[sheetNo].lastRow = sliceLen
+
+	return nil
+}
+
+// setAutoFilter sets AutoFilter covering rowLength columns down to lastRow
+func (g *Generator) setAutoFilter(sheetNo int, sheet *xlsx.Sheet, rowLength, lastRow int) {
+	if g.settings[sheetNo].autoFilter {
 		sheet.AutoFilter = &xlsx.AutoFilter{
 			TopLeftCell:     "A1",
-			BottomRightCell: fmt.Sprintf("%s%d", gointtoletters.IntToLetters(rowLength), sliceLen),
+			BottomRightCell: fmt.Sprintf("%s%d", gointtoletters.IntToLetters(rowLength), lastRow),
 		}
 	}
+}
 
-	if g.freezeFirstColumn {
-		sheet.SheetViews = append(sheet.SheetViews, xlsx.SheetView{
-			Pane: &xlsx.Pane{
-				XSplit:      0,
-				YSplit:      1,
-				TopLeftCell: "A2",
-				ActivePane:  "bottomLeft",
-				State:       "frozen",
-			},
-		})
-	} else if g.freezeFirstRow {
-		sheet.SheetViews = append(sheet.SheetViews, xlsx.SheetView{
-			Pane: &xlsx.Pane{
-				XSplit:      1,
-				YSplit:      0,
-				TopLeftCell: "B1",
-				ActivePane:  "topRight",
-				State:       "frozen",
-			},
-		})
+// setSheetViews sets SheetViews with frozen panes
+func (g *Generator) setSheetViews(sheetNo int, sheet *xlsx.Sheet) {
+	settings := g.settings[sheetNo]
+	rows, cols := settings.freezeRows, settings.freezeCols
+	if settings.freezeAuto {
+		// Only headers row is written above data
+		rows = max(rows, 1)
+		cols = max(cols, settings.freezeKeyCols)
 	}
 
-	return nil
+	if rows <= 0 && cols <= 0 {
+		return
+	}
+	rows, cols = max(rows, 0), max(cols, 0)
+
+	activePane := "bottomRight"
+	switch {
+	case cols == 0:
+		activePane = "bottomLeft"
+	case rows == 0:
+		activePane = "topRight"
+	}
+
+	sheet.SheetViews = append(sheet.SheetViews, xlsx.SheetView{
+		Pane: &xlsx.Pane{
+			XSplit:      float64(cols),
+			YSplit:      float64(rows),
+			TopLeftCell: xlsx.GetCellIDStringFromCoords(cols, rows),
+			ActivePane:  activePane,
+			State:       "frozen",
+		},
+	})
+}
+
+// boolToInt returns 1 for true and 0 for false
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
 }
 
+// AddData adds headers and rows to sheet, data can be a slice, iter.Seq[T], iter.Seq2[int, T] or a channel of tagged structs
 func (g *Generator) AddData(sheetNo int, data interface{}) error {
-	sliceLen, err := validateAndLength(data)
+	if err := validateSource(data); err != nil {
+		return err
+	}
+
+	rowLength, rows, err := g.processData(sheetNo, data, true)
+	if err != nil {
+		return err
+	}
+
+	// Header row is followed by every written row
+	if err := g.setSheetProperties(sheetNo, rowLength, rows+1); err != nil {
+		return err
+	}
+
+	sheet, err := g.GetSheet(sheetNo)
 	if err != nil {
 		return err
 	}
 
-	rowLength, mapValues, err := g.processData(sheetNo, data, sliceLen)
+	return g.addTotalRow(sheetNo, sheet, rowLength, rows+1)
+}
+
+// AddTemplate adds headers row for type T followed by rows empty rows with cell formats of their columns.
+// It is meant for blank import templates, which need column widths, formats and data validations but no data.
+func AddTemplate[T any](g *Generator, sheetNo int, rows int) error {
+	itemType := reflect.TypeFor[T]()
+	if itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		return fmt.Errorf("template type has to be a struct, got %s", itemType)
+	}
+
+	rowLength, err := g.processHeaders(sheetNo, itemType)
 	if err != nil {
 		return err
 	}
 
-	if err := g.checkConsistentMapKeys(mapValues); err != nil {
+	sheet, err := g.GetSheet(sheetNo)
+	if err != nil {
 		return err
 	}
+	for i := 0; i < rows; i++ {
+		row := sheet.AddRow()
+		for index, options := range g.customOptions[sheetNo] {
+			if options.Skip {
+				continue
+			}
+
+			cell := g.layoutCell(row, sheetNo, index)
+			if options.Formula != "" {
+				if err := g.setCellFormula(sheetNo, cell, options.Formula); err != nil {
+					return err
+				}
+			}
+			options.ApplyToCell(cell)
+		}
+	}
 
-	if err := g.setSheetProperties(sheetNo, rowLength, sliceLen); err != nil {
+	if err := g.setSheetProperties(sheetNo, rowLength, rows+1); err != nil {
 		return err
 	}
 
-	return nil
+	return g.addTotalRow(sheetNo, sheet, rowLength, rows+1)
 }
 
-func (g *Generator) processData(sheetNo int, data interface{}, sliceLen int) (int, map[string][]reflect.Value, error) {
+// processData consumes data source, it returns headers row length and number of written data rows.
+// Headers are derived from the item type, map field columns are created for keys of every item.
+// Without headers, items can not bring map keys which were not collected before.
+// Empty data is an error, unless headers are written with GeneratorOptionEmptyTemplate.
+func (g *Generator) processData(sheetNo int, data interface{}, withHeaders bool) (int, int, error) {
+	if len(g.mapKeys) <= sheetNo {
+		return 0, 0, &ErrSheetNotFound{}
+	}
+
+	allowEmpty := withHeaders && g.emptyTemplate
+	itemType := sourceItemType(data)
+	source := reflect.ValueOf(data)
+	if source.Kind() == reflect.Slice && source.Len() == 0 && !allowEmpty {
+		return 0, 0, &ErrEmptySlice{}
+	}
+
 	var rowLength int
-	var mapFields []string
-	mapValues := make(map[string][]reflect.Value)
+	if withHeaders {
+		if helpers.HasMapFields(itemType) {
+			var err error
+			source, err = g.collectMapKeys(sheetNo, itemType, source)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
 
-	for i := 0; i < sliceLen; i++ {
-		itemValue := reflect.ValueOf(data).Index(i)
-		itemType := itemValue.Type()
+		var err error
+		rowLength, err = g.processHeaders(sheetNo, itemType)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var rows, items int
+	err := helpers.ForEachSourceItem(source, func(itemValue reflect.Value) error {
+		items++
 
 		// Handle pointers
-		if itemType.Kind() == reflect.Ptr {
+		if itemValue.Kind() == reflect.Ptr {
 			if itemValue.IsNil() {
-				continue
+				return nil
 			}
 
 			itemValue = itemValue.Elem()
-			itemType = itemValue.Type()
 		}
 
-		// Process headers for the first item
-		if i == 0 {
-			var err error
-			rowLength, err = g.processHeaders(sheetNo, itemType, itemValue, &mapFields)
-			if err != nil {
-				return 0, nil, err
-			}
+		if !withHeaders && !g.hasMapKeys(sheetNo, itemType, itemValue) {
+			return &ErrInconsistentMapKeys{}
 		}
 
 		// Process the item
-		if err := g.processItem(sheetNo, itemType, itemValue, mapFields, mapValues); err != nil {
-			return 0, nil, err
+		if err := g.processItem(sheetNo, itemType, itemValue); err != nil {
+			return err
 		}
+		rows++
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if items == 0 && !allowEmpty {
+		return 0, 0, &ErrEmptySlice{}
 	}
 
-	return rowLength, mapValues, nil
+	return rowLength, rows, nil
 }
 
-func (g *Generator) checkConsistentMapKeys(mapValues map[string][]reflect.Value) error {
-	for _, maps := range mapValues {
-		if sameKeys, err := helpers.AreAllMapKeysSame(maps); err != nil || !sameKeys {
-			return &ErrInconsistentMapKeys{}
+// collectMapKeys collects map keys of every item of source, items of sources other than slices are buffered
+// to be consumed again, the returned source should be used instead of the given one
+func (g *Generator) collectMapKeys(sheetNo int, itemType reflect.Type, source reflect.Value) (reflect.Value, error) {
+	buffered := source
+	if source.Kind() != reflect.Slice {
+		elemType, _ := helpers.SourceElemType(source.Type())
+		buffered = reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
+	}
+
+	err := helpers.ForEachSourceItem(source, func(itemValue reflect.Value) error {
+		if source.Kind() != reflect.Slice {
+			buffered = reflect.Append(buffered, itemValue)
+		}
+
+		helpers.CollectMapKeys(g.mapKeys[sheetNo], itemType, reflect.Indirect(itemValue))
+
+		return nil
+	})
+
+	return buffered, err
+}
+
+// hasMapKeys checks if every map key of item was already collected for sheet
+func (g *Generator) hasMapKeys(sheetNo int, itemType reflect.Type, itemValue reflect.Value) bool {
+	keys := make(map[helpers.MapField][]string)
+	helpers.CollectMapKeys(keys, itemType, itemValue)
+	for field, fieldKeys := range keys {
+		for _, key := range fieldKeys {
+			if _, found := slices.BinarySearch(g.mapKeys[sheetNo][field], key); !found {
+				return false
+			}
 		}
 	}
-	return nil
+
+	return true
 }
 
 func (g *Generator) parseTagValue(sheetNo int, f reflect.StructField) (*CustomOptions, error) {
@@ -270,10 +577,22 @@ func (g *Generator) parseTagValue(sheetNo int, f reflect.StructField) (*CustomOp
 		return nil, err
 	}
 
-	options, err := g.NewCustomOptions(tagValue)
+	settings := g.settings[sheetNo]
+	options, err := newCustomOptions(tagValue, settings.customDropdown, g.styles)
 	if err != nil {
 		return nil, err
 	}
+	if !options.Skip {
+		// Columns are selected and renamed by tag name, so options keyed by column name keep working
+		excluded := len(settings.includeColumns) > 0 && !slices.Contains(settings.includeColumns, options.ColumnName)
+		options.Skip = excluded || slices.Contains(settings.excludeColumns, options.ColumnName)
+		options.Header = settings.headerNames[options.ColumnName]
+	}
+	if options.CustomDropdown.Rows > 0 && len(options.CustomDropdown.Values) == 0 {
+		if values, ok := enumDropdownValues(f.Type); ok {
+			options.CustomDropdown.Values = values
+		}
+	}
 
 	g.customOptions[sheetNo] = append(g.customOptions[sheetNo], options)
 
@@ -282,5 +601,15 @@ func (g *Generator) parseTagValue(sheetNo int, f reflect.StructField) (*CustomOp
 
 // SaveTo writes generated xlsx to io.Writer
 func (g *Generator) SaveTo(out io.Writer) error {
-	return g.wb.Write(out)
+	parts, err := g.wb.MakeStreamParts()
+	if err != nil {
+		return err
+	}
+
+	err = g.patchParts(parts)
+	if err != nil {
+		return err
+	}
+
+	return writeParts(out, parts)
 }
@@ -1,12 +1,16 @@
 package autoxlsx
 
 import (
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
 )
 
 type WithNilStruct struct {
@@ -703,3 +707,356 @@ func TestGenerator_GetSheet(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestGenerator_AddData_Sources(t *testing.T) {
+	items := []*WithNilStruct{
+		{ID: 1, NillableString: &exampleString},
+		nil,
+		{ID: 3},
+	}
+	channel := func() <-chan *WithNilStruct {
+		ch := make(chan *WithNilStruct, len(items))
+		for _, item := range items {
+			ch <- item
+		}
+		close(ch)
+		return ch
+	}
+
+	tests := []struct {
+		name    string
+		arg     interface{}
+		want    *xlsx.AutoFilter
+		wantErr bool
+	}{
+		{
+			name: "slice",
+			arg:  items,
+			want: &xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"},
+		},
+		{
+			name: "iter.Seq",
+			arg:  slices.Values(items),
+			want: &xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"},
+		},
+		{
+			name: "iter.Seq2",
+			arg:  slices.All(items),
+			want: &xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"},
+		},
+		{
+			name: "channel",
+			arg:  channel(),
+			want: &xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"},
+		},
+		{
+			name:    "empty iter.Seq",
+			arg:     slices.Values([]WithNilStruct{}),
+			wantErr: true,
+		},
+		{
+			name:    "map",
+			arg:     map[string]WithNilStruct{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(GeneratorOptionAutoFilter{})
+			sheetNo, err := generator.AddSheet("test")
+			if err != nil {
+				t.Fatalf("unable to prepare sheet, err= %v", err)
+			}
+
+			err = generator.AddData(sheetNo, tt.arg)
+
+			if (err == nil) == tt.wantErr {
+				t.Errorf("AddData got err= %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				sheet, _ := generator.GetSheet(sheetNo)
+				if diff := cmp.Diff(tt.want, sheet.AutoFilter); diff != "" {
+					t.Errorf("AddData auto filter differs from expected (-want +got)\n%s", diff)
+				}
+				if diff := cmp.Diff(3, sheet.MaxRow); diff != "" {
+					t.Errorf("AddData rows count differs from expected (-want +got)\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+// sheetValues returns text of every sheet cell
+func sheetValues(t *testing.T, sheet *xlsx.Sheet) [][]string {
+	t.Helper()
+
+	var values [][]string
+	err := sheet.ForEachRow(func(row *xlsx.Row) error {
+		var rowValues []string
+		err := row.ForEachCell(func(cell *xlsx.Cell) error {
+			rowValues = append(rowValues, cell.Value)
+			return nil
+		})
+		values = append(values, rowValues)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to read sheet, err= %v", err)
+	}
+
+	return values
+}
+
+func TestGenerator_AddData_Headers(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  interface{}
+		want [][]string
+	}{
+		{
+			name: "nil first item",
+			arg: []*WithNilStruct{
+				nil,
+				{ID: 2, NillableString: &exampleString},
+			},
+			want: [][]string{
+				{"id", "string"},
+				{"2", "example"},
+			},
+		},
+		{
+			name: "map keys of every item",
+			arg: []UnmarshalStruct{
+				{ID: 1, Values: map[string]*float64{"b": &exampleFloat}},
+				{ID: 2, Values: map[string]*float64{"a": &exampleFloat, "c": &exampleFloat}},
+			},
+			want: [][]string{
+				{"id", "a", "b", "c", "name", "created", "active"},
+				{"1", "", "2.2", "", "", "-693593", "0"},
+				{"2", "2.2", "", "2.2", "", "-693593", "0"},
+			},
+		},
+		{
+			name: "nil nested struct",
+			arg: []WithNestedStruct2{
+				{WithNilStruct: WithNilStruct{ID: 1}},
+			},
+			want: [][]string{
+				{"id", "string", "id", "string"},
+				{"", "", "1", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator()
+			sheetNo, err := generator.AddSheet("test")
+			if err != nil {
+				t.Fatalf("unable to prepare sheet, err= %v", err)
+			}
+
+			err = generator.AddData(sheetNo, tt.arg)
+			if err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			if diff := cmp.Diff(tt.want, sheetValues(t, sheet)); diff != "" {
+				t.Errorf("AddData cells differ from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+type WithIntKeyMap struct {
+	ID     int            `xlsx:"id"`
+	Values map[int]string `xlsx:"*"`
+}
+
+type WithUntaggedIntKeyMap struct {
+	ID     int `xlsx:"id"`
+	Values map[int]string
+}
+
+func TestGenerator_AddData_MapKeys(t *testing.T) {
+	generator := NewGenerator()
+	sheetNo, err := generator.AddSheet("test")
+	if err != nil {
+		t.Fatalf("unable to prepare sheet, err= %v", err)
+	}
+
+	err = generator.AddData(sheetNo, []WithIntKeyMap{{ID: 1, Values: map[int]string{1: "a"}}})
+	if !errors.As(err, new(*ErrInvalidMapKey)) {
+		t.Errorf("AddData got err= %v, want ErrInvalidMapKey", err)
+	}
+
+	sheetNo, err = generator.AddSheet("untagged")
+	if err != nil {
+		t.Fatalf("unable to prepare sheet, err= %v", err)
+	}
+	err = generator.AddData(sheetNo, []WithUntaggedIntKeyMap{{ID: 1, Values: map[int]string{1: "a"}}})
+	if err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	sheet, _ := generator.GetSheet(sheetNo)
+	if diff := cmp.Diff([][]string{{"id"}, {"1"}}, sheetValues(t, sheet)); diff != "" {
+		t.Errorf("AddData cells differ from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestAddTemplate(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionCustomDropdown(map[string][]string{"custom_dropdown": {"a", "b"}}))
+	sheetNo, err := generator.AddSheet("template")
+	if err != nil {
+		t.Fatalf("unable to prepare sheet, err= %v", err)
+	}
+
+	err = AddTemplate[SomeStruct](generator, sheetNo, 2)
+	if err != nil {
+		t.Fatalf("AddTemplate got err= %v", err)
+	}
+
+	sheet, _ := generator.GetSheet(sheetNo)
+	want := [][]string{
+		{"id", "value", "custom_dropdown"},
+		{"", "", ""},
+		{"", "", ""},
+	}
+	if diff := cmp.Diff(want, sheetValues(t, sheet)); diff != "" {
+		t.Errorf("AddTemplate cells differ from expected (-want +got)\n%s", diff)
+	}
+
+	cell, _ := sheet.Cell(2, 1)
+	if diff := cmp.Diff("0.000000000000", cell.NumFmt); diff != "" {
+		t.Errorf("AddTemplate cell format differs from expected (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(1, len(sheet.DataValidations)); diff != "" {
+		t.Errorf("AddTemplate data validations count differs from expected (-want +got)\n%s", diff)
+	}
+
+	if err := AddTemplate[int](generator, sheetNo, 1); err == nil {
+		t.Errorf("AddTemplate got no error for non struct type")
+	}
+}
+
+func TestGenerator_AddData_EmptyTemplate(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionEmptyTemplate{})
+	sheetNo, err := generator.AddSheet("template")
+	if err != nil {
+		t.Fatalf("unable to prepare sheet, err= %v", err)
+	}
+
+	err = generator.AddData(sheetNo, []WithNilStruct{})
+	if err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+
+	sheet, _ := generator.GetSheet(sheetNo)
+	if diff := cmp.Diff([][]string{{"id", "string"}}, sheetValues(t, sheet)); diff != "" {
+		t.Errorf("AddData cells differ from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestGenerator_SheetOptions(t *testing.T) {
+	list := sheetList.New(map[string]interface{}{
+		"data":   []WithNilStruct{{ID: 1}},
+		"lookup": []WithNilStruct{{ID: 2}},
+	}, sheetList.WithSort(true), sheetList.WithSheetOptions("lookup", SheetOptionAutoFilter(false), SheetOptionHidden(true)))
+
+	generator := NewGenerator(GeneratorOptionAutoFilter{})
+	if err := generator.GenerateXLSX(list); err != nil {
+		t.Fatalf("GenerateXLSX got err= %v", err)
+	}
+
+	data, _ := generator.GetSheet(0)
+	lookup, _ := generator.GetSheet(1)
+	if diff := cmp.Diff(&xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "B2"}, data.AutoFilter); diff != "" {
+		t.Errorf("data sheet auto filter differs from expected (-want +got)\n%s", diff)
+	}
+	if lookup.AutoFilter != nil {
+		t.Errorf("lookup sheet auto filter got %v, want nil", lookup.AutoFilter)
+	}
+	if data.Hidden || !lookup.Hidden {
+		t.Errorf("sheets hidden got %v/%v, want false/true", data.Hidden, lookup.Hidden)
+	}
+
+	sheetNo, err := generator.AddSheet("dropdown", SheetOptionCustomDropdown(map[string][]string{"other": {"x"}}))
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if diff := cmp.Diff(map[string][]string{"other": {"x"}}, generator.settings[sheetNo].customDropdown); diff != "" {
+		t.Errorf("sheet custom dropdown differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestGenerator_FreezePanes(t *testing.T) {
+	type keyStruct struct {
+		Name  string `xlsx:"name"`
+		ID    int    `xlsx:"id,freeze"`
+		Value int    `xlsx:"value"`
+	}
+
+	tests := []struct {
+		name    string
+		options []GeneratorOption
+		sheet   []SheetOption
+		want    *xlsx.Pane
+	}{
+		{
+			name:    "first row",
+			options: []GeneratorOption{GeneratorOptionFreezeFirstRow{}},
+			want:    &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"},
+		},
+		{
+			name:    "first column",
+			options: []GeneratorOption{GeneratorOptionFreezeFirstColumn{}},
+			want:    &xlsx.Pane{XSplit: 1, TopLeftCell: "B1", ActivePane: "topRight", State: "frozen"},
+		},
+		{
+			name:    "first row and column",
+			options: []GeneratorOption{GeneratorOptionFreezeFirstRow{}, GeneratorOptionFreezeFirstColumn{}},
+			want:    &xlsx.Pane{XSplit: 1, YSplit: 1, TopLeftCell: "B2", ActivePane: "bottomRight", State: "frozen"},
+		},
+		{
+			name:    "rows and columns",
+			options: []GeneratorOption{GeneratorOptionFreezePanes{Rows: 2, Cols: 3}},
+			want:    &xlsx.Pane{XSplit: 3, YSplit: 2, TopLeftCell: "D3", ActivePane: "bottomRight", State: "frozen"},
+		},
+		{
+			name:    "auto",
+			options: []GeneratorOption{GeneratorOptionFreezeAuto{}},
+			want:    &xlsx.Pane{XSplit: 2, YSplit: 1, TopLeftCell: "C2", ActivePane: "bottomRight", State: "frozen"},
+		},
+		{
+			name:    "sheet option overrides generator option",
+			options: []GeneratorOption{GeneratorOptionFreezePanes{Rows: 2, Cols: 3}},
+			sheet:   []SheetOption{SheetOptionFreezePanes(0, 0)},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.options...)
+			sheetNo, err := generator.AddSheet("sheet", tt.sheet...)
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			if err := generator.AddData(sheetNo, []keyStruct{{Name: "a", ID: 1, Value: 2}}); err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			var got *xlsx.Pane
+			if len(sheet.SheetViews) > 0 {
+				got = sheet.SheetViews[0].Pane
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("pane differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,14 +1,34 @@
 package autoxlsx
 
 import (
+	"cmp"
 	"reflect"
+	"slices"
 
 	"github.com/tealeg/xlsx/v3"
 
 	"github.com/arturwwl/autoxlsx/pkg/helpers"
 )
 
-// AddTableHeaders creates headers row
+// headerColumn holds column collected from fields of type, before it's placed in the sheet
+type headerColumn struct {
+	index   int // index of options in custom options of sheet, data cells find their column by it
+	options *CustomOptions
+	key     string // key of map field column, empty for other columns
+}
+
+// name returns header name of column
+func (c headerColumn) name() string {
+	if c.key != "" {
+		return c.key
+	}
+
+	return c.options.ColumnName
+}
+
+// AddTableHeaders creates headers row for type t. Columns of map fields are created for keys collected from
+// every row of sheet's data, keys of value (if valid) are added to them. Columns of nested and embedded structs
+// are collected first, so the layout given by column order and order: tags is known before any cell is written.
 func (g *Generator) AddTableHeaders(row *xlsx.Row, sheetNo int, t reflect.Type, value reflect.Value, count int) (int, bool, error) {
 	if row == nil {
 		sheet, err := g.GetSheet(sheetNo)
@@ -19,27 +39,60 @@ func (g *Generator) AddTableHeaders(row *xlsx.Row, sheetNo int, t reflect.Type,
 		row = sheet.AddRow()
 	}
 
+	if value.IsValid() {
+		helpers.CollectMapKeys(g.mapKeys[sheetNo], t, value)
+	}
+
+	start := len(g.customOptions[sheetNo])
+	columns, hasMapField, err := g.collectTableHeaders(sheetNo, t)
+	if err != nil {
+		return 0, false, err
+	}
+	g.orderColumns(sheetNo, columns)
+
+	layout := slices.Repeat([]int{-1}, len(columns))
 	var currentCount int
+	for _, column := range columns {
+		if column.options.Skip {
+			continue
+		}
+
+		err = g.addTableHeaderCell(row, sheetNo, count+currentCount, column.options, column.key)
+		if err != nil {
+			return 0, false, err
+		}
+
+		layout[column.index-start] = count + currentCount
+		currentCount++
+	}
+	settings := g.settings[sheetNo]
+	settings.layout = append(settings.layout[:start], layout...)
+
+	return currentCount, hasMapField, nil
+}
+
+// collectTableHeaders returns columns of fields of type t in order of fields, skipped columns included
+func (g *Generator) collectTableHeaders(sheetNo int, t reflect.Type) ([]headerColumn, bool, error) {
+	var columns []headerColumn
 	var hasMapField bool
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		added, withMapField, err := g.addTableHeader(row, sheetNo, f, value, count, nil)
+		added, withMapField, err := g.collectTableHeader(sheetNo, t, f)
 		if err != nil {
-			return 0, false, err
+			return nil, false, err
 		}
 
 		if !hasMapField {
 			hasMapField = withMapField
 		}
 
-		count += added
-		currentCount += added
+		columns = append(columns, added...)
 	}
 
-	return currentCount, hasMapField, nil
+	return columns, hasMapField, nil
 }
 
-func (g *Generator) addTableHeader(row *xlsx.Row, sheetNo int, field reflect.StructField, data reflect.Value, currentCount int, fieldOptions *CustomOptions) (int, bool, error) {
+func (g *Generator) collectTableHeader(sheetNo int, owner reflect.Type, field reflect.StructField) ([]headerColumn, bool, error) {
 	fv := field.Type
 	kind := fv.Kind()
 
@@ -49,36 +102,66 @@ func (g *Generator) addTableHeader(row *xlsx.Row, sheetNo int, field reflect.Str
 	}
 
 	if reflect.Map == kind {
-		return g.addMapTableHeader(row, sheetNo, field, data, currentCount)
+		return g.collectMapTableHeader(sheetNo, owner, field)
 	}
 
 	if kind == reflect.Struct {
-		if !helpers.IsCommonGoStruct(fv) {
-			return g.AddTableHeaders(row, sheetNo, fv, data, currentCount)
+		if !isValueField(&field, fv) {
+			return g.collectTableHeaders(sheetNo, fv)
 		}
 	}
 
+	index := len(g.customOptions[sheetNo])
 	fieldOptions, err := g.parseTagValue(sheetNo, field)
 	if err != nil {
-		return 0, false, err
+		return nil, false, err
 	}
 
-	if fieldOptions.Skip {
-		return 0, false, nil
-	}
+	return []headerColumn{{index: index, options: fieldOptions}}, false, nil
+}
 
-	err = g.addTableHeaderCell(row, sheetNo, currentCount, fieldOptions, "")
-	if err != nil {
-		return 0, false, err
+// orderColumns sorts columns named by column order option first, in its order. Columns with order: tag follow,
+// sorted by it, and the rest keep order of fields.
+func (g *Generator) orderColumns(sheetNo int, columns []headerColumn) {
+	columnOrder := g.settings[sheetNo].columnOrder
+	slices.SortStableFunc(columns, func(a, b headerColumn) int {
+		return rankColumn(a.name(), a.options, columnOrder).compare(rankColumn(b.name(), b.options, columnOrder))
+	})
+}
+
+// columnRank holds place of column in layout, Unmarshal ranks fields the same way to find map columns
+type columnRank struct {
+	group int
+	order int
+}
+
+// rankColumn ranks column named name, columns named in columnOrder come first, then columns with order: tag
+func rankColumn(name string, options *CustomOptions, columnOrder []string) columnRank {
+	if i := slices.Index(columnOrder, name); i >= 0 {
+		return columnRank{group: 0, order: i}
+	}
+	if options.Order != 0 {
+		return columnRank{group: 1, order: options.Order}
 	}
 
-	return 1, false, nil
+	return columnRank{group: 2}
+}
+
+// compare compares ranks like cmp.Compare
+func (r columnRank) compare(other columnRank) int {
+	return cmp.Or(cmp.Compare(r.group, other.group), cmp.Compare(r.order, other.order))
 }
 
 func (g *Generator) addTableHeaderCell(row *xlsx.Row, sheetNo int, currentCount int, fieldOptions *CustomOptions, customValue string) error {
 	cell := row.AddCell()
 
-	err := fieldOptions.ApplyToHeaderCell(cell, currentCount, customValue)
+	g.setDropdownLabels(sheetNo, fieldOptions)
+	err := g.setDropdownLookup(sheetNo, currentCount, fieldOptions)
+	if err != nil {
+		return err
+	}
+
+	err = fieldOptions.ApplyToHeaderCell(cell, currentCount, customValue)
 	if err != nil {
 		return err
 	}
@@ -92,6 +175,32 @@ func (g *Generator) addTableHeaderCell(row *xlsx.Row, sheetNo int, currentCount
 		return err
 	}
 
+	if fieldOptions.Freeze {
+		// Key columns are frozen together with every column on their left
+		g.settings[sheetNo].freezeKeyCols = max(g.settings[sheetNo].freezeKeyCols, currentCount+1)
+	}
+
+	name := headerColumn{options: fieldOptions, key: customValue}.name()
+	settings := g.settings[sheetNo]
+	if settings.columns == nil {
+		settings.columns = make(map[string]int)
+	}
+	settings.columns[name] = currentCount
+
+	err = g.addConditionalColumn(sheetNo, currentCount, fieldOptions, name)
+	if err != nil {
+		return err
+	}
+
+	err = g.addTotalColumn(sheetNo, currentCount, fieldOptions)
+	if err != nil {
+		return err
+	}
+
+	if fieldOptions.Note != "" {
+		g.addComment(sheetNo, currentCount, 0, fieldOptions.Note)
+	}
+
 	col := xlsx.NewColForRange(currentCount+1, currentCount+1)
 	sheet.Cols.Add(col)
 
@@ -100,31 +209,63 @@ func (g *Generator) addTableHeaderCell(row *xlsx.Row, sheetNo int, currentCount
 	return nil
 }
 
-func (g *Generator) addMapTableHeader(row *xlsx.Row, sheetNo int, field reflect.StructField, data reflect.Value, currentCount int) (int, bool, error) {
-	var added int
-
-	keys, err := helpers.GetMapKeys(data.FieldByName(field.Name))
-	if err != nil {
-		return 0, false, err
+func (g *Generator) collectMapTableHeader(sheetNo int, owner reflect.Type, field reflect.StructField) ([]headerColumn, bool, error) {
+	if err := checkMapKey(field); err != nil {
+		return nil, false, err
 	}
 
+	var columns []headerColumn
+
+	keys := g.mapKeys[sheetNo][helpers.MapField{Owner: owner, Name: field.Name}]
 	for _, key := range keys {
+		// Options are parsed for every key, so data cells can find them by column
+		index := len(g.customOptions[sheetNo])
 		fieldOptions, err := g.parseTagValue(sheetNo, field)
 		if err != nil {
-			return 0, false, err
+			return nil, false, err
 		}
 
-		if fieldOptions.Skip {
-			return 0, false, nil
-		}
+		columns = append(columns, headerColumn{index: index, options: fieldOptions, key: key})
+	}
 
-		err = g.addTableHeaderCell(row, sheetNo, currentCount+added, fieldOptions, key)
-		if err != nil {
-			return 0, false, err
+	return columns, true, nil
+}
+
+// addConditionalColumn registers conditional formatting rules of column named name from tag and generator options
+func (g *Generator) addConditionalColumn(sheetNo int, col int, fieldOptions *CustomOptions, name string) error {
+	settings := g.settings[sheetNo]
+	rules := slices.Concat(fieldOptions.ConditionalFormats, settings.conditionalFormat[name])
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for _, rule := range rules {
+		if _, err := g.styles.resolve(rule.Style); err != nil {
+			return err
 		}
+	}
+	settings.conditionalColumns = append(settings.conditionalColumns, conditionalColumn{col: col, rules: rules})
 
-		added++
+	return nil
+}
+
+// checkMapKey returns error for map field with columns which keys are not strings, fields without columns are accepted
+func checkMapKey(field reflect.StructField) error {
+	ft := field.Type
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if ft.Key().Kind() == reflect.String {
+		return nil
+	}
+
+	options, err := newCustomOptions(field.Tag.Get("xlsx"), nil, nil)
+	if err != nil {
+		return err
+	}
+	if options.Skip {
+		return nil
 	}
 
-	return added, true, nil
+	return &ErrInvalidMapKey{Field: field.Name, Key: ft.Key()}
 }
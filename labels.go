@@ -0,0 +1,121 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// DropdownItem holds value of labeled dropdown and label shown in the list and in cells instead of it
+type DropdownItem struct {
+	Value string
+	Label string
+}
+
+// UnmarshalOption is option of Unmarshal and UnmarshalSheet
+type UnmarshalOption interface{}
+
+// unmarshalOptionLabeledDropdown holds option for reading values of labeled dropdowns
+type unmarshalOptionLabeledDropdown struct {
+	values map[string][]DropdownItem
+}
+
+// UnmarshalOptionLabeledDropdown creates option reading labels of dropdowns keyed by column name as their values,
+// it's the reverse of GeneratorOptionLabeledDropdown
+func UnmarshalOptionLabeledDropdown(values map[string][]DropdownItem) UnmarshalOption {
+	return unmarshalOptionLabeledDropdown{values: values}
+}
+
+// unmarshalOptionHeaderNames holds option for reading renamed headers
+type unmarshalOptionHeaderNames struct {
+	values map[string]string
+}
+
+// UnmarshalOptionHeaderNames creates option matching headers keyed by tag name with fields of tag names,
+// it's the reverse of GeneratorOptionHeaderNames
+func UnmarshalOptionHeaderNames(values map[string]string) UnmarshalOption {
+	return unmarshalOptionHeaderNames{values: values}
+}
+
+// unmarshalOptionColumnOrder holds option for order of columns
+type unmarshalOptionColumnOrder struct {
+	names []string
+}
+
+// UnmarshalOptionColumnOrder creates option reading sheets written with GeneratorOptionColumnOrder,
+// it's needed to find columns of map fields moved by the order
+func UnmarshalOptionColumnOrder(names []string) UnmarshalOption {
+	return unmarshalOptionColumnOrder{names: names}
+}
+
+// setDropdownLabels uses labels of labeled dropdown of column as dropdown values
+func (g *Generator) setDropdownLabels(sheetNo int, options *CustomOptions) {
+	items, ok := g.settings[sheetNo].labeledDropdown[options.ColumnName]
+	if !ok || options.CustomDropdown.Rows <= 0 {
+		return
+	}
+
+	options.CustomDropdown.Values = make([]string, 0, len(items))
+	options.CustomDropdown.Labels = make(map[string]string, len(items))
+	for _, item := range items {
+		options.CustomDropdown.Values = append(options.CustomDropdown.Values, item.Label)
+		options.CustomDropdown.Labels[item.Value] = item.Label
+	}
+}
+
+// setDropdownLabel replaces value of cell holding fv with its dropdown label, values without label are kept
+func setDropdownLabel(cell *xlsx.Cell, fieldOptions *CustomOptions, fv reflect.Value) {
+	if len(fieldOptions.CustomDropdown.Labels) == 0 || !fv.IsValid() {
+		return
+	}
+
+	if label, ok := fieldOptions.CustomDropdown.Labels[fmt.Sprint(fv.Interface())]; ok {
+		cell.SetString(label)
+	}
+}
+
+// headerNames returns headers keyed by tag name from options
+func headerNames(options []UnmarshalOption) map[string]string {
+	names := make(map[string]string)
+	for _, option := range options {
+		switch v := option.(type) {
+		case unmarshalOptionHeaderNames:
+			maps.Copy(names, v.values)
+		}
+	}
+
+	return names
+}
+
+// columnOrder returns column order from options
+func columnOrder(options []UnmarshalOption) []string {
+	var names []string
+	for _, option := range options {
+		switch v := option.(type) {
+		case unmarshalOptionColumnOrder:
+			names = v.names
+		}
+	}
+
+	return names
+}
+
+// dropdownValues returns values of labeled dropdowns keyed by column name and label
+func dropdownValues(options []UnmarshalOption) map[string]map[string]string {
+	values := make(map[string]map[string]string)
+	for _, option := range options {
+		switch v := option.(type) {
+		case unmarshalOptionLabeledDropdown:
+			for column, items := range v.values {
+				values[column] = make(map[string]string, len(items))
+				for _, item := range items {
+					values[column][item.Label] = item.Value
+				}
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,60 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type LabeledStruct struct {
+	Country  string  `xlsx:"country,dropdown:10"`
+	Previous *string `xlsx:"previous,dropdown:10"`
+}
+
+func TestGenerator_LabeledDropdown(t *testing.T) {
+	countries := []DropdownItem{{Value: "PL", Label: "Poland"}, {Value: "DE", Label: "Germany"}}
+	previous := "DE"
+	data := []LabeledStruct{{Country: "PL", Previous: &previous}, {Country: "XX"}}
+
+	buff := new(bytes.Buffer)
+	err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff,
+		GeneratorOptionLabeledDropdown(map[string][]DropdownItem{"country": countries, "previous": countries}))
+	if err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+
+	wantValidations := []string{`&#34;Poland,Germany&#34;`, `&#34;Poland,Germany&#34;`}
+	if diff := cmp.Diff(wantValidations, validationFormulas(t, buff.Bytes())); diff != "" {
+		t.Errorf("dropdown formulas differ from expected (-want +got)\n%s", diff)
+	}
+
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	var cells []string
+	for _, ref := range [][2]int{{1, 0}, {1, 1}, {2, 0}} {
+		cell, err := wb.Sheet["sheet"].Cell(ref[0], ref[1])
+		if err != nil {
+			t.Fatalf("Cell got err= %v", err)
+		}
+		cells = append(cells, cell.Value)
+	}
+	if diff := cmp.Diff([]string{"Poland", "Germany", "XX"}, cells); diff != "" {
+		t.Errorf("labeled cells differ from expected (-want +got)\n%s", diff)
+	}
+
+	var got []LabeledStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}),
+		UnmarshalOptionLabeledDropdown(map[string][]DropdownItem{"country": countries, "previous": countries}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("Unmarshal differs from expected (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,153 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/pkg/helpers"
+)
+
+// Hyperlink holds value written as link. URL starting with # is a location inside the workbook,
+// e.g. "#'Orders'!A5", Text is shown instead of URL when not empty.
+type Hyperlink struct {
+	URL  string
+	Text string
+}
+
+// Link holds link options of a column parsed from tag. Template is link: option with {value} and {row}
+// placeholders, Sheet and Column (link-sheet: and link-column:) link to the row of Sheet which has the same value
+// in Column, Column defaults to the name of linking column.
+type Link struct {
+	Template string
+	Sheet    string
+	Column   string
+}
+
+// valuePlaceholder is replaced in link template with value of the cell
+const valuePlaceholder = "{value}"
+
+var hyperlinkType = reflect.TypeFor[Hyperlink]()
+
+// defaultLinkStyle is used by link cells, it's Excel's hyperlink style
+var defaultLinkStyle = CellStyle{FontColor: "FF0563C1", Underline: true}
+
+// isValueStruct reports whether struct type t is written as a single cell instead of columns of its fields
+func isValueStruct(t reflect.Type) bool {
+	return t == hyperlinkType || t == commentedType || helpers.IsCommonGoStruct(t) || isMarshalerType(t)
+}
+
+// isValueField reports whether struct field of struct type t is written as a single cell, field is nil for map values
+func isValueField(field *reflect.StructField, t reflect.Type) bool {
+	return isValueStruct(t) || (field != nil && isTextValueField(*field, t))
+}
+
+// isLinkValue reports whether value is written as link
+func isLinkValue(fv reflect.Value) bool {
+	return fv.IsValid() && fv.Type() == hyperlinkType
+}
+
+// IsZero reports whether no link option is set
+func (l Link) IsZero() bool {
+	return l == Link{}
+}
+
+// setLinkValue writes Hyperlink value to cell
+func setLinkValue(cell *xlsx.Cell, fv reflect.Value) {
+	link := fv.Interface().(Hyperlink)
+	text := link.Text
+	if text == "" {
+		text = strings.TrimPrefix(link.URL, "#")
+	}
+
+	cell.SetString(text)
+	if link.URL != "" {
+		setCellLink(cell, link.URL)
+	}
+}
+
+// addCellLink adds link of tag options to cell holding value fv, nil values and empty strings are not linked
+func (g *Generator) addCellLink(sheetNo int, cell *xlsx.Cell, fieldOptions *CustomOptions, fv reflect.Value) {
+	if fieldOptions.Link.IsZero() || !fv.IsValid() || fv.IsZero() && fv.Kind() == reflect.String {
+		return
+	}
+	value := fmt.Sprint(fv.Interface())
+
+	if fieldOptions.Link.Sheet != "" {
+		column := fieldOptions.Link.Column
+		if column == "" {
+			column = fieldOptions.ColumnName
+		}
+		setKeyLink(cell, fieldOptions.Link.Sheet, column, value, fv.Kind() == reflect.String)
+	} else {
+		if !strings.HasPrefix(fieldOptions.Link.Template, "#") {
+			value = url.PathEscape(value)
+		}
+		rowNo := strconv.Itoa(g.settings[sheetNo].rowOffset + cell.Row.GetCoordinate() + 1)
+
+		link := strings.ReplaceAll(fieldOptions.Link.Template, valuePlaceholder, value)
+		setCellLink(cell, strings.ReplaceAll(link, "{"+rowPlaceholder+"}", rowNo))
+	}
+}
+
+// setCellLink adds link to cell keeping its value. Links starting with # point to a location in the workbook,
+// http(s) links are external and other schemes (e.g. mailto:) are written as HYPERLINK formula.
+func setCellLink(cell *xlsx.Cell, link string) {
+	lower := strings.ToLower(link)
+	switch {
+	case strings.HasPrefix(link, "#"):
+		cell.Hyperlink = xlsx.Hyperlink{Location: link[1:]}
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		// SetHyperlink replaces cell value with link text, value is restored so numbers stay numbers
+		value, cellType := cell.Value, cell.Type()
+		cell.SetHyperlink(link, "", "")
+		switch cellType {
+		case xlsx.CellTypeNumeric:
+			cell.SetNumeric(value)
+		case xlsx.CellTypeBool:
+			cell.SetBool(value == "1")
+		default:
+			cell.SetString(value)
+		}
+	default:
+		setLinkFormula(cell, quoteFormulaString(link))
+	}
+}
+
+// setKeyLink adds link to the row of sheet which has value in column with header column. The row is found
+// with MATCH when the workbook is opened, so links are valid regardless of sheets order and sorting of rows.
+func setKeyLink(cell *xlsx.Cell, sheet, column, value string, isString bool) {
+	if isString {
+		value = quoteFormulaString(value)
+	}
+
+	sheetRef := "'" + strings.ReplaceAll(sheet, "'", "''") + "'!"
+	columnNo := fmt.Sprintf("MATCH(%s,%s$1:$1,0)", quoteFormulaString(column), sheetRef)
+	rowNo := fmt.Sprintf("MATCH(%s,INDEX(%s$A:$XFD,0,%s),0)", value, sheetRef, columnNo)
+
+	setLinkFormula(cell, fmt.Sprintf(`"#%s"&ADDRESS(%s,%s)`, strings.ReplaceAll(sheetRef, `"`, `""`), rowNo, columnNo))
+}
+
+// setLinkFormula writes HYPERLINK formula to cell, current value of the cell is kept as formula result
+func setLinkFormula(cell *xlsx.Cell, location string) {
+	display := cell.Value
+	if cell.Type() != xlsx.CellTypeNumeric {
+		display = quoteFormulaString(display)
+	}
+	formula := fmt.Sprintf("HYPERLINK(%s,%s)", location, display)
+
+	if cell.Type() == xlsx.CellTypeNumeric {
+		cell.SetFormula(formula)
+		return
+	}
+	cell.SetStringFormula(formula)
+}
+
+// quoteFormulaString returns s as formula string literal
+func quoteFormulaString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,123 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type LinkStruct struct {
+	ID       int       `xlsx:"id,link:https://app/orders/{value}"`
+	Customer string    `xlsx:"customer,link-sheet:customers,link-column:name"`
+	Row      string    `xlsx:"row,link:#'orders'!A{row}"`
+	Docs     Hyperlink `xlsx:"docs"`
+	Missing  *string   `xlsx:"missing,link:https://app/{value}"`
+}
+
+type linkCell struct {
+	Value   string
+	Formula string
+	Link    xlsx.Hyperlink
+}
+
+// sheetLinks returns values, formulas and hyperlinks of the first data row of sheet
+func sheetLinks(t *testing.T, sheet *xlsx.Sheet) []linkCell {
+	t.Helper()
+
+	row, err := sheet.Row(1)
+	if err != nil {
+		t.Fatalf("Row got err= %v", err)
+	}
+
+	var got []linkCell
+	err = row.ForEachCell(func(cell *xlsx.Cell) error {
+		got = append(got, linkCell{Value: cell.Value, Formula: cell.Formula(), Link: cell.Hyperlink})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachCell got err= %v", err)
+	}
+
+	return got
+}
+
+func TestGenerator_Links(t *testing.T) {
+	data := []LinkStruct{{ID: 7, Customer: `A "B"`, Row: "self", Docs: Hyperlink{URL: "mailto:x@y.z", Text: "mail"}}}
+	want := []linkCell{
+		{Value: "7", Link: xlsx.Hyperlink{Link: "https://app/orders/7"}},
+		{
+			Value: `A "B"`,
+			Formula: `HYPERLINK("#'customers'!"&ADDRESS(MATCH("A ""B""",INDEX('customers'!$A:$XFD,0,` +
+				`MATCH("name",'customers'!$1:$1,0)),0),MATCH("name",'customers'!$1:$1,0)),"A ""B""")`,
+		},
+		{Value: "self", Link: xlsx.Hyperlink{Location: "'orders'!A2"}},
+		{Value: "mail", Formula: `HYPERLINK("mailto:x@y.z","mail")`},
+		{},
+	}
+
+	generator := NewGenerator()
+	sheetNo, err := generator.AddSheet("orders")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(sheetNo, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	sheet, _ := generator.GetSheet(sheetNo)
+	if diff := cmp.Diff(want, sheetLinks(t, sheet)); diff != "" {
+		t.Errorf("link cells differ from expected (-want +got)\n%s", diff)
+	}
+	if cell, err := sheet.Cell(1, 0); err != nil || !cell.GetStyle().Font.Underline {
+		t.Errorf("link cell got err= %v, want underlined font", err)
+	}
+
+	buff := new(bytes.Buffer)
+	s := NewStreamGenerator(buff)
+	sheetNo, err = s.AddSheet("orders")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(sheetNo, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+	if rels := readPart(t, buff.Bytes(), "xl/worksheets/_rels/sheet1.xml.rels"); !strings.Contains(rels, `Target="https://app/orders/7"`) {
+		t.Errorf("stream sheet relationships got %s, want link target", rels)
+	}
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	if diff := cmp.Diff(want, sheetLinks(t, wb.Sheet["orders"])); diff != "" {
+		t.Errorf("stream link cells differ from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestUnmarshal_Hyperlink(t *testing.T) {
+	type linkRow struct {
+		Docs Hyperlink `xlsx:"docs"`
+		Row  Hyperlink `xlsx:"row"`
+	}
+	want := []linkRow{{Docs: Hyperlink{URL: "https://app/docs", Text: "docs"}, Row: Hyperlink{URL: "#'sheet'!A1", Text: "top"}}}
+
+	buff := new(bytes.Buffer)
+	if err := Marshal(sheetList.New(map[string]interface{}{"sheet": want}), buff); err != nil {
+		t.Fatalf("unable to prepare workbook, err= %v", err)
+	}
+
+	var got []linkRow
+	err := Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal return value differs from expected (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,170 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// lookupSheetName is name of the very hidden sheet holding dropdown values which do not fit inline list
+const lookupSheetName = "_autoxlsx_lookup"
+
+// dependentNamePrefix starts names of ranges holding lists of dependent dropdowns
+const dependentNamePrefix = "_autoxlsx_list"
+
+// maxInlineDropdownLength is limit of Excel for inline list validation, including quotes
+const maxInlineDropdownLength = 255
+
+// DropdownLookup holds range of dropdown values written to lookup sheet
+type DropdownLookup struct {
+	Sheet  string
+	Column int
+	Rows   int
+}
+
+// lookupSheet holds lists written to lookup sheet, every list has its own column. Groups are lists of dependent
+// dropdowns, each of them has keys list and named range for every key.
+type lookupSheet struct {
+	sheetNo int
+	columns map[string]int
+	groups  map[string]int
+}
+
+// ref returns absolute reference of lookup range
+func (l *DropdownLookup) ref() string {
+	return fmt.Sprintf("'%s'!%s:%s", strings.ReplaceAll(l.Sheet, "'", "''"),
+		xlsx.GetCellIDStringFromCoordsWithFixed(l.Column, 0, true, true),
+		xlsx.GetCellIDStringFromCoordsWithFixed(l.Column, l.Rows-1, true, true))
+}
+
+// fitsInlineDropdown reports whether values can be written as inline list validation
+func fitsInlineDropdown(values []string) bool {
+	return utf8.RuneCountInString(strings.Join(values, ","))+2 <= maxInlineDropdownLength
+}
+
+// setDropdownLookup moves dropdown values which do not fit inline list to lookup sheet,
+// lists of dependent dropdown in column col are always written to lookup sheet
+func (g *Generator) setDropdownLookup(sheetNo, col int, options *CustomOptions) error {
+	dropdown := &options.CustomDropdown
+	if dropdown.Rows <= 0 || dropdown.Sheet != "" {
+		return nil
+	}
+
+	if dropdown.Depends != "" {
+		return g.setDependentDropdown(sheetNo, col, options)
+	}
+
+	if fitsInlineDropdown(dropdown.Values) {
+		return nil
+	}
+
+	lookup, err := g.addLookupList(dropdown.Values)
+	if err != nil {
+		return err
+	}
+	dropdown.Lookup = lookup
+
+	return nil
+}
+
+// addLookupList writes values to a column of lookup sheet, which is created on first use. The same list is written once.
+func (g *Generator) addLookupList(values []string) (*DropdownLookup, error) {
+	if g.lookup == nil {
+		sheetNo, err := g.AddSheet(lookupSheetName, SheetOptionHidden(true))
+		if err != nil {
+			return nil, err
+		}
+		// Lookup sheet does not use generator options like freeze panes or auto filter
+		g.settings[sheetNo] = &sheetSettings{hidden: true, veryHidden: true}
+		g.lookup = &lookupSheet{sheetNo: sheetNo, columns: make(map[string]int), groups: make(map[string]int)}
+	}
+
+	key := strings.Join(values, "\x00")
+	col, ok := g.lookup.columns[key]
+	if !ok {
+		sheet, err := g.GetSheet(g.lookup.sheetNo)
+		if err != nil {
+			return nil, err
+		}
+
+		col = len(g.lookup.columns)
+		for row, value := range values {
+			cell, err := sheet.Cell(row, col)
+			if err != nil {
+				return nil, err
+			}
+			cell.SetString(value)
+		}
+		g.lookup.columns[key] = col
+	}
+
+	return &DropdownLookup{Sheet: lookupSheetName, Column: col, Rows: len(values)}, nil
+}
+
+// setDependentDropdown sets list source of dropdown which values depend on value of parent column in the same row.
+// The row of parent value in keys list selects named range with dependent values through INDIRECT.
+func (g *Generator) setDependentDropdown(sheetNo, col int, options *CustomOptions) error {
+	dropdown := &options.CustomDropdown
+	parentCol, ok := g.settings[sheetNo].columns[dropdown.Depends]
+	if !ok || parentCol >= col {
+		return &ErrDropdownParentNotFound{Name: dropdown.Depends}
+	}
+
+	group, keys, err := g.addLookupGroup(g.settings[sheetNo].dependentDropdown[options.ColumnName])
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return nil
+	}
+
+	dropdown.Formula = fmt.Sprintf(`INDIRECT("%s%d_"&MATCH(%s,%s,0))`,
+		dependentNamePrefix, group, xlsx.GetCellIDStringFromCoords(parentCol, 1), keys.ref())
+
+	return nil
+}
+
+// addLookupGroup writes keys of lists and every list to lookup sheet and defines names of list ranges,
+// nil keys are returned for no lists. The same lists are written once.
+func (g *Generator) addLookupGroup(lists map[string][]string) (int, *DropdownLookup, error) {
+	if len(lists) == 0 {
+		return 0, nil, nil
+	}
+
+	keys := slices.Sorted(maps.Keys(lists))
+	keysLookup, err := g.addLookupList(keys)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var groupKey strings.Builder
+	for _, key := range keys {
+		groupKey.WriteString(key + "\x00" + strings.Join(lists[key], "\x00") + "\x01")
+	}
+	if group, ok := g.lookup.groups[groupKey.String()]; ok {
+		return group, keysLookup, nil
+	}
+
+	group := len(g.lookup.groups) + 1
+	for i, key := range keys {
+		// Named range cannot be empty, INDIRECT of missing name gives no list
+		if len(lists[key]) == 0 {
+			continue
+		}
+		values, err := g.addLookupList(lists[key])
+		if err != nil {
+			return 0, nil, err
+		}
+		err = g.wb.AddDefinedName(xlsx.DefinedName{Name: fmt.Sprintf("%s%d_%d", dependentNamePrefix, group, i+1), Data: values.ref()})
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	g.lookup.groups[groupKey.String()] = group
+
+	return group, keysLookup, nil
+}
@@ -0,0 +1,191 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+)
+
+type LookupStruct struct {
+	Country string `xlsx:"country,dropdown:10"`
+	Origin  string `xlsx:"origin,dropdown:10"`
+	Size    string `xlsx:"size,dropdown:10"`
+}
+
+func TestGenerator_DropdownLookup(t *testing.T) {
+	var countries []string
+	for i := 0; i < 100; i++ {
+		countries = append(countries, fmt.Sprintf("Country %d", i))
+	}
+	dropdowns := GeneratorOptionCustomDropdown(map[string][]string{"country": countries, "origin": countries, "size": {"S", "M"}})
+	wantValidations := []string{`&#39;_autoxlsx_lookup&#39;!$A$1:$A$100`, `&#39;_autoxlsx_lookup&#39;!$A$1:$A$100`, `&#34;S,M&#34;`}
+	data := []LookupStruct{{Country: "Country 1"}}
+
+	generator := NewGenerator(dropdowns)
+	if _, err := generator.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(0, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	if _, err := generator.AddSheet("other"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+	checkDropdownLookup(t, buff.Bytes(), wantValidations, countries)
+
+	buff = new(bytes.Buffer)
+	s := NewStreamGenerator(buff, dropdowns)
+	if _, err := s.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(0, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if _, err := s.AddSheet("other"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+	checkDropdownLookup(t, buff.Bytes(), wantValidations, countries)
+}
+
+// checkDropdownLookup checks dropdown formulas of the first sheet and values of very hidden lookup sheet
+func checkDropdownLookup(t *testing.T, file []byte, wantValidations, wantValues []string) {
+	t.Helper()
+
+	if diff := cmp.Diff(wantValidations, validationFormulas(t, file)); diff != "" {
+		t.Errorf("dropdown formulas differ from expected (-want +got)\n%s", diff)
+	}
+
+	if workbook := readPart(t, file, "xl/workbook.xml"); !strings.Contains(workbook, `name="_autoxlsx_lookup" sheetId="2" r:id="rId2" state="veryHidden"`) {
+		t.Errorf("workbook got %s, want very hidden lookup sheet", workbook)
+	}
+
+	wb, err := xlsx.OpenBinary(file)
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	if got := wb.Sheet[lookupSheetName].MaxRow; got != len(wantValues) {
+		t.Errorf("lookup sheet got %d rows, want %d", got, len(wantValues))
+	}
+	var values []string
+	for row := 0; row < len(wantValues); row++ {
+		cell, err := wb.Sheet[lookupSheetName].Cell(row, 0)
+		if err != nil {
+			t.Fatalf("Cell got err= %v", err)
+		}
+		values = append(values, cell.Value)
+	}
+	if diff := cmp.Diff(wantValues, values); diff != "" {
+		t.Errorf("lookup values differ from expected (-want +got)\n%s", diff)
+	}
+	if _, ok := wb.Sheet["other"]; !ok || len(wb.Sheets) != 3 {
+		t.Errorf("workbook got %d sheets, want sheet, lookup and other", len(wb.Sheets))
+	}
+}
+
+type DependentStruct struct {
+	Country string `xlsx:"country,dropdown:10"`
+	Region  string `xlsx:"region,dropdown:10,dropdown-depends:country"`
+	City    string `xlsx:"city,dropdown:10,dropdown-depends:region"`
+}
+
+func TestGenerator_DependentDropdown(t *testing.T) {
+	options := []GeneratorOption{
+		GeneratorOptionCustomDropdown(map[string][]string{"country": {"PL", "DE"}}),
+		GeneratorOptionDependentDropdown(map[string]map[string][]string{
+			"region": {"PL": {"Mazowieckie", "Pomorskie"}, "DE": {"Bayern"}},
+			"city":   {"Mazowieckie": {"Warszawa"}, "Pomorskie": {"Gdańsk", "Gdynia"}, "Bayern": {"München"}},
+		}),
+	}
+	wantValidations := []string{
+		`&#34;PL,DE&#34;`,
+		`INDIRECT(&#34;_autoxlsx_list1_&#34;&amp;MATCH(A2,&#39;_autoxlsx_lookup&#39;!$A$1:$A$2,0))`,
+		`INDIRECT(&#34;_autoxlsx_list2_&#34;&amp;MATCH(B2,&#39;_autoxlsx_lookup&#39;!$D$1:$D$3,0))`,
+	}
+	wantNames := []string{
+		`<definedName name="_autoxlsx_list1_1">&#39;_autoxlsx_lookup&#39;!$B$1:$B$1</definedName>`,
+		`<definedName name="_autoxlsx_list1_2">&#39;_autoxlsx_lookup&#39;!$C$1:$C$2</definedName>`,
+		`<definedName name="_autoxlsx_list2_1">&#39;_autoxlsx_lookup&#39;!$E$1:$E$1</definedName>`,
+		`<definedName name="_autoxlsx_list2_2">&#39;_autoxlsx_lookup&#39;!$F$1:$F$1</definedName>`,
+		`<definedName name="_autoxlsx_list2_3">&#39;_autoxlsx_lookup&#39;!$G$1:$G$2</definedName>`,
+	}
+	data := []DependentStruct{{Country: "PL", Region: "Pomorskie", City: "Gdynia"}}
+
+	generator := NewGenerator(options...)
+	if _, err := generator.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(0, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+	checkDependentDropdown(t, buff.Bytes(), wantValidations, wantNames)
+
+	buff = new(bytes.Buffer)
+	s := NewStreamGenerator(buff, options...)
+	if _, err := s.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(0, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+	checkDependentDropdown(t, buff.Bytes(), wantValidations, wantNames)
+
+	generator = NewGenerator(options...)
+	if _, err := generator.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	err := generator.AddData(0, []struct {
+		City string `xlsx:"city,dropdown:10,dropdown-depends:region"`
+	}{{}})
+	var parentErr *ErrDropdownParentNotFound
+	if !errors.As(err, &parentErr) {
+		t.Errorf("AddData got err= %v, want ErrDropdownParentNotFound", err)
+	}
+}
+
+// checkDependentDropdown checks dropdown formulas of the first sheet and defined names of lists
+func checkDependentDropdown(t *testing.T, file []byte, wantValidations, wantNames []string) {
+	t.Helper()
+
+	if diff := cmp.Diff(wantValidations, validationFormulas(t, file)); diff != "" {
+		t.Errorf("dropdown formulas differ from expected (-want +got)\n%s", diff)
+	}
+
+	workbook := readPart(t, file, "xl/workbook.xml")
+	for _, name := range wantNames {
+		if !strings.Contains(workbook, name) {
+			t.Errorf("workbook got %s, want %s", workbook, name)
+		}
+	}
+}
+
+// validationFormulas returns the first formula of every data validation of the first sheet of file
+func validationFormulas(t *testing.T, file []byte) []string {
+	t.Helper()
+
+	var formulas []string
+	for _, dv := range sheetValidations(t, file) {
+		formula, _, _ := strings.Cut(dv[strings.Index(dv, "<formula1>")+len("<formula1>"):], "</formula1>")
+		formulas = append(formulas, formula)
+	}
+
+	return formulas
+}
@@ -0,0 +1,101 @@
+package autoxlsx
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/pkg/helpers"
+)
+
+// CellMarshaler is implemented by types which write their value to cell themselves
+type CellMarshaler interface {
+	MarshalXLSXCell(cell *xlsx.Cell) error
+}
+
+var (
+	cellMarshalerType = reflect.TypeFor[CellMarshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+)
+
+// isMarshalerType reports whether t or pointer to it marshals itself with CellMarshaler. TextMarshaler and Stringer
+// do not make struct a single cell, nested structs often have String for logging only.
+func isMarshalerType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(cellMarshalerType)
+}
+
+// isTextValueField reports whether struct field of struct type t is written as a single cell with TextMarshaler
+// or Stringer. It's so for fields with their own tag of types without tagged fields, like decimals.
+func isTextValueField(field reflect.StructField, t reflect.Type) bool {
+	if _, ok := field.Tag.Lookup("xlsx"); !ok {
+		return false
+	}
+	if !reflect.PointerTo(t).Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(stringerType) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("xlsx"); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// marshalCellValue writes value of types with custom marshaling to cell. CellMarshaler is used first,
+// then registered leaf types, encoding.TextMarshaler and fmt.Stringer. False is returned for other types.
+func marshalCellValue(data reflect.Value, cell *xlsx.Cell) (bool, error) {
+	if marshaler, ok := interfaceOf[CellMarshaler](data); ok {
+		return true, marshaler.MarshalXLSXCell(cell)
+	}
+
+	leaf, isLeaf := helpers.GetLeafType(data.Type())
+	if isLeaf && leaf.Value != nil {
+		cell.SetValue(leaf.Value(data))
+		if leaf.Format != "" {
+			cell.SetFormat(leaf.Format)
+		}
+		return true, nil
+	}
+	if marshaler, ok := interfaceOf[encoding.TextMarshaler](data); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return true, err
+		}
+		cell.SetString(string(text))
+		return true, nil
+	}
+
+	if stringer, ok := interfaceOf[fmt.Stringer](data); ok {
+		cell.SetString(stringer.String())
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// interfaceOf returns data as T, methods with pointer receivers are used through address of data or its copy
+func interfaceOf[T any](data reflect.Value) (T, bool) {
+	var zero T
+	t := reflect.TypeFor[T]()
+	if data.Type().Implements(t) {
+		if data.Kind() == reflect.Pointer && data.IsNil() {
+			return zero, false
+		}
+		return data.Interface().(T), true
+	}
+
+	if reflect.PointerTo(data.Type()).Implements(t) {
+		if !data.CanAddr() {
+			c := reflect.New(data.Type()).Elem()
+			c.Set(data)
+			data = c
+		}
+		return data.Addr().Interface().(T), true
+	}
+
+	return zero, false
+}
@@ -0,0 +1,232 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/pkg/helpers"
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type Money struct {
+	Cents int64
+}
+
+func (m Money) MarshalXLSXCell(cell *xlsx.Cell) error {
+	cell.SetFloatWithFormat(float64(m.Cents)/100, "0.00")
+	return nil
+}
+
+type Color int
+
+func (c Color) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+type MarshalerStruct struct {
+	Price    Money          `xlsx:"price"`
+	Address  netip.Addr     `xlsx:"address"`
+	Color    Color          `xlsx:"color"`
+	Name     sql.NullString `xlsx:"name"`
+	Count    sql.NullInt64  `xlsx:"count"`
+	Duration time.Duration  `xlsx:"duration"`
+	Big      *big.Int       `xlsx:"big"`
+}
+
+type marshaledCell struct {
+	Value  string
+	Format string
+}
+
+func TestGenerator_CellMarshaler(t *testing.T) {
+	// Structs are written with TextMarshaler only when registered as leaf type
+	helpers.RegisterLeafType(reflect.TypeOf(netip.Addr{}), helpers.LeafType{})
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	data := []MarshalerStruct{
+		{
+			Price:    Money{Cents: 1250},
+			Address:  netip.MustParseAddr("10.0.0.1"),
+			Color:    1,
+			Name:     sql.NullString{String: "a", Valid: true},
+			Count:    sql.NullInt64{Int64: 3, Valid: true},
+			Duration: 90 * time.Minute,
+			Big:      huge,
+		},
+		{Name: sql.NullString{String: "ignored"}, Big: big.NewInt(7)},
+	}
+	want := [][]marshaledCell{
+		{{"price", "general"}, {"address", "general"}, {"color", "general"}, {"name", "general"}, {"count", "general"}, {"duration", "general"}, {"big", "general"}},
+		{{"12.5", "0.00"}, {"10.0.0.1", "general"}, {"green", "general"}, {"a", "general"}, {"3", "general"}, {"0.0625", "[h]:mm:ss"}, {"123456789012345678901234567890", "general"}},
+		{{"0", "0.00"}, {"", "general"}, {"red", "general"}, {"", "general"}, {"", "general"}, {"0", "[h]:mm:ss"}, {"7", "general"}},
+	}
+
+	buff := new(bytes.Buffer)
+	if err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff); err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	var got [][]marshaledCell
+	err = wb.Sheet["sheet"].ForEachRow(func(row *xlsx.Row) error {
+		var cells []marshaledCell
+		err := row.ForEachCell(func(cell *xlsx.Cell) error {
+			cells = append(cells, marshaledCell{Value: cell.Value, Format: cell.GetNumberFormat()})
+			return nil
+		})
+		got = append(got, cells)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ForEachRow got err= %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("marshaled cells differ from expected (-want +got)\n%s", diff)
+	}
+
+	type readRow struct {
+		Name     sql.NullString `xlsx:"name"`
+		Count    sql.NullInt64  `xlsx:"count"`
+		Duration time.Duration  `xlsx:"duration"`
+		Big      *big.Int       `xlsx:"big"`
+		Address  netip.Addr     `xlsx:"address"`
+	}
+	var read []readRow
+	if err := UnmarshalSheet(wb.Sheet["sheet"], &read); err != nil {
+		t.Errorf("UnmarshalSheet got err= %v", err)
+	}
+	wantRead := []readRow{
+		{Name: data[0].Name, Count: data[0].Count, Duration: data[0].Duration, Big: huge, Address: data[0].Address},
+		{Big: big.NewInt(7)},
+	}
+	if diff := cmp.Diff(wantRead, read, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 }), cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+		t.Errorf("UnmarshalSheet differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+type Point struct {
+	X int `xlsx:"x"`
+	Y int `xlsx:"y"`
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+type StringerStruct struct {
+	Name string `xlsx:"name"`
+	Pos  Point
+}
+
+func TestGenerator_NestedStringer(t *testing.T) {
+	data := []StringerStruct{{Name: "a", Pos: Point{X: 1, Y: 2}}}
+
+	buff := new(bytes.Buffer)
+	if err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff); err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	row, err := wb.Sheet["sheet"].Row(0)
+	if err != nil {
+		t.Fatalf("Row got err= %v", err)
+	}
+	var headers []string
+	_ = row.ForEachCell(func(cell *xlsx.Cell) error {
+		headers = append(headers, cell.Value)
+		return nil
+	})
+	if diff := cmp.Diff([]string{"name", "x", "y"}, headers); diff != "" {
+		t.Errorf("headers differ from expected (-want +got)\n%s", diff)
+	}
+
+	var got []StringerStruct
+	if err := UnmarshalSheet(wb.Sheet["sheet"], &got); err != nil {
+		t.Fatalf("UnmarshalSheet got err= %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("UnmarshalSheet differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+type Dec struct {
+	v   int64
+	exp int32
+}
+
+func (d Dec) String() string {
+	return big.NewFloat(float64(d.v)*math.Pow10(int(d.exp))).Text('f', int(-d.exp))
+}
+
+func (d Dec) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Dec) UnmarshalText(text []byte) error {
+	whole, frac, _ := strings.Cut(string(text), ".")
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return err
+	}
+	d.v, d.exp = v, -int32(len(frac))
+
+	return nil
+}
+
+type Label struct {
+	text string
+}
+
+func (l Label) String() string {
+	return "<" + l.text + ">"
+}
+
+type DecimalStruct struct {
+	Name   string `xlsx:"name"`
+	Amount Dec    `xlsx:"amount"`
+	Label  Label  `xlsx:"label"`
+}
+
+func TestGenerator_TextValueStruct(t *testing.T) {
+	data := []DecimalStruct{{Name: "a", Amount: Dec{v: 1250, exp: -2}, Label: Label{text: "x"}}}
+
+	buff := new(bytes.Buffer)
+	if err := Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff); err != nil {
+		t.Fatalf("Marshal got err= %v", err)
+	}
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	if diff := cmp.Diff([][]string{{"name", "amount", "label"}, {"a", "12.50", "<x>"}}, sheetValues(t, wb.Sheet["sheet"])); diff != "" {
+		t.Errorf("cells differ from expected (-want +got)\n%s", diff)
+	}
+
+	type readRow struct {
+		Name   string `xlsx:"name"`
+		Amount Dec    `xlsx:"amount"`
+	}
+	var got []readRow
+	if err := UnmarshalSheet(wb.Sheet["sheet"], &got); err != nil {
+		t.Fatalf("UnmarshalSheet got err= %v", err)
+	}
+	if diff := cmp.Diff([]readRow{{Name: "a", Amount: data[0].Amount}}, got, cmp.AllowUnexported(Dec{})); diff != "" {
+		t.Errorf("UnmarshalSheet differs from expected (-want +got)\n%s", diff)
+	}
+}
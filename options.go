@@ -1,6 +1,7 @@
 package autoxlsx
 
 import (
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,44 +20,362 @@ type GeneratorOptionFreezeFirstColumn struct{}
 // GeneratorOptionFreezeFirstRow holds option for freeze first row
 type GeneratorOptionFreezeFirstRow struct{}
 
+// GeneratorOptionFreezePanes holds option for freezing Rows leading rows and Cols leading columns
+type GeneratorOptionFreezePanes struct {
+	Rows int
+	Cols int
+}
+
+// GeneratorOptionFreezeAuto holds option for freezing headers row and leading columns up to the last one
+// tagged with freeze
+type GeneratorOptionFreezeAuto struct{}
+
+// GeneratorOptionTotalRow holds option for totals row added below data when columns have total: tag.
+// Label is written to the first column, unless it has total itself. Style is bold when empty.
+type GeneratorOptionTotalRow struct {
+	Label string
+	Style CellStyle
+}
+
+// GeneratorOptionEmptyTemplate holds option for writing only headers row (import template) for empty data,
+// instead of returning ErrEmptySlice
+type GeneratorOptionEmptyTemplate struct{}
+
+// GeneratorOptionSanitizeStrings holds option for escaping strings which would be read as formulas,
+// e.g. when the sheet is saved as CSV. Columns tagged with no-sanitize are written as they are.
+// Unmarshal removes the escaping apostrophe.
+type GeneratorOptionSanitizeStrings struct{}
+
+// GeneratorOptionSafeMode holds option for writing untrusted data, it enables GeneratorOptionSanitizeStrings
+type GeneratorOptionSafeMode struct{}
+
+// generatorOptionColumnOrder holds option for order of columns
+type generatorOptionColumnOrder struct {
+	names []string
+}
+
+// generatorOptionColumns holds option for columns written to sheets
+type generatorOptionColumns struct {
+	names []string
+}
+
+// generatorOptionExcludeColumns holds option for columns left out of sheets
+type generatorOptionExcludeColumns struct {
+	names []string
+}
+
+// generatorOptionHeaderNames holds option for renamed headers
+type generatorOptionHeaderNames struct {
+	values map[string]string
+}
+
 // generatorOptionCustomDropdown holds option for custom dropdown
 type generatorOptionCustomDropdown struct {
 	values map[string][]string
 }
 
+// generatorOptionDependentDropdown holds option for dependent dropdowns
+type generatorOptionDependentDropdown struct {
+	values map[string]map[string][]string
+}
+
+// generatorOptionLabeledDropdown holds option for labeled dropdowns
+type generatorOptionLabeledDropdown struct {
+	values map[string][]DropdownItem
+}
+
 // generatorOptionHiddenSheets holds option for hidden sheets
 type generatorOptionHiddenSheets struct {
 	values []string
 }
 
+// generatorOptionStyles holds option for shared style registry
+type generatorOptionStyles struct {
+	registry *StyleRegistry
+}
+
+// GeneratorOptionStyles creates option using registry for styles, so named styles can be shared between generators
+func GeneratorOptionStyles(registry *StyleRegistry) GeneratorOption {
+	return generatorOptionStyles{registry: registry}
+}
+
+// generatorOptionConditionalFormat holds option for conditional formatting
+type generatorOptionConditionalFormat struct {
+	values map[string][]ConditionalFormat
+}
+
+// GeneratorOptionConditionalFormat creates conditional formatting option, rules are keyed by column name
+// and applied in addition to the ones from tags
+func GeneratorOptionConditionalFormat(values map[string][]ConditionalFormat) GeneratorOption {
+	return generatorOptionConditionalFormat{values: values}
+}
+
 // GeneratorOptionCustomDropdown creates custom dropdown option
 func GeneratorOptionCustomDropdown(values map[string][]string) GeneratorOption {
 	return generatorOptionCustomDropdown{values: values}
 }
 
+// GeneratorOptionDependentDropdown creates dependent dropdown option, values are keyed by column name
+// and then by value of the column given by dropdown-depends: tag
+func GeneratorOptionDependentDropdown(values map[string]map[string][]string) GeneratorOption {
+	return generatorOptionDependentDropdown{values: values}
+}
+
+// GeneratorOptionLabeledDropdown creates labeled dropdown option, items are keyed by column name. Labels are shown
+// in dropdown list and in cells instead of values, UnmarshalOptionLabeledDropdown reads them back as values.
+func GeneratorOptionLabeledDropdown(values map[string][]DropdownItem) GeneratorOption {
+	return generatorOptionLabeledDropdown{values: values}
+}
+
+// GeneratorOptionColumnOrder creates option placing columns with given header names first, in given order.
+// Other columns follow in order of order: tags and fields, unknown names are ignored.
+func GeneratorOptionColumnOrder(names []string) GeneratorOption {
+	return generatorOptionColumnOrder{names: names}
+}
+
+// GeneratorOptionColumns creates option writing only columns with given tag names, all columns are written when empty
+func GeneratorOptionColumns(names []string) GeneratorOption {
+	return generatorOptionColumns{names: names}
+}
+
+// GeneratorOptionExcludeColumns creates option leaving out columns with given tag names
+func GeneratorOptionExcludeColumns(names []string) GeneratorOption {
+	return generatorOptionExcludeColumns{names: names}
+}
+
+// GeneratorOptionHeaderNames creates option writing headers keyed by tag name instead of tag names. Other options,
+// formulas and links keep referring to columns by tag name, UnmarshalOptionHeaderNames reads such sheets back.
+func GeneratorOptionHeaderNames(values map[string]string) GeneratorOption {
+	return generatorOptionHeaderNames{values: values}
+}
+
 // GeneratorOptionHiddenSheets creates hidden sheets option
 func GeneratorOptionHiddenSheets(values []string) GeneratorOption {
 	return generatorOptionHiddenSheets{values: values}
 }
 
+// SheetOption holds sheet option, it overrides generator option of the same kind for a single sheet
+type SheetOption interface{}
+
+// sheetOptionAutoFilter holds sheet option for auto filter
+type sheetOptionAutoFilter struct {
+	enabled bool
+}
+
+// sheetOptionSanitizeStrings holds sheet option for escaping strings which would be read as formulas
+type sheetOptionSanitizeStrings struct {
+	enabled bool
+}
+
+// sheetOptionFreezeFirstColumn holds sheet option for freeze first column
+type sheetOptionFreezeFirstColumn struct {
+	enabled bool
+}
+
+// sheetOptionFreezeFirstRow holds sheet option for freeze first row
+type sheetOptionFreezeFirstRow struct {
+	enabled bool
+}
+
+// sheetOptionFreezePanes holds sheet option for freezing leading rows and columns
+type sheetOptionFreezePanes struct {
+	rows int
+	cols int
+}
+
+// sheetOptionFreezeAuto holds sheet option for freezing headers row and columns tagged with freeze
+type sheetOptionFreezeAuto struct {
+	enabled bool
+}
+
+// sheetOptionTotalRow holds sheet option for totals row
+type sheetOptionTotalRow struct {
+	totalRow GeneratorOptionTotalRow
+}
+
+// sheetOptionHidden holds sheet option for hidden sheet
+type sheetOptionHidden struct {
+	enabled bool
+}
+
+// sheetOptionColumnOrder holds sheet option for order of columns
+type sheetOptionColumnOrder struct {
+	names []string
+}
+
+// sheetOptionColumns holds sheet option for columns written to sheet
+type sheetOptionColumns struct {
+	names []string
+}
+
+// sheetOptionExcludeColumns holds sheet option for columns left out of sheet
+type sheetOptionExcludeColumns struct {
+	names []string
+}
+
+// sheetOptionHeaderNames holds sheet option for renamed headers
+type sheetOptionHeaderNames struct {
+	values map[string]string
+}
+
+// sheetOptionCustomDropdown holds sheet option for custom dropdown
+type sheetOptionCustomDropdown struct {
+	values map[string][]string
+}
+
+// sheetOptionDependentDropdown holds sheet option for dependent dropdowns
+type sheetOptionDependentDropdown struct {
+	values map[string]map[string][]string
+}
+
+// sheetOptionLabeledDropdown holds sheet option for labeled dropdowns
+type sheetOptionLabeledDropdown struct {
+	values map[string][]DropdownItem
+}
+
+// sheetOptionConditionalFormat holds sheet option for conditional formatting
+type sheetOptionConditionalFormat struct {
+	values map[string][]ConditionalFormat
+}
+
+// SheetOptionAutoFilter creates option enabling or disabling auto filter of a sheet
+func SheetOptionAutoFilter(enabled bool) SheetOption {
+	return sheetOptionAutoFilter{enabled: enabled}
+}
+
+// SheetOptionFreezeFirstColumn creates option enabling or disabling freeze first column of a sheet
+func SheetOptionFreezeFirstColumn(enabled bool) SheetOption {
+	return sheetOptionFreezeFirstColumn{enabled: enabled}
+}
+
+// SheetOptionFreezeFirstRow creates option enabling or disabling freeze first row of a sheet
+func SheetOptionFreezeFirstRow(enabled bool) SheetOption {
+	return sheetOptionFreezeFirstRow{enabled: enabled}
+}
+
+// SheetOptionFreezePanes creates option freezing rows leading rows and cols leading columns of a sheet
+func SheetOptionFreezePanes(rows, cols int) SheetOption {
+	return sheetOptionFreezePanes{rows: rows, cols: cols}
+}
+
+// SheetOptionFreezeAuto creates option enabling or disabling freezing headers row and columns tagged with freeze
+func SheetOptionFreezeAuto(enabled bool) SheetOption {
+	return sheetOptionFreezeAuto{enabled: enabled}
+}
+
+// SheetOptionTotalRow creates option setting label and style of totals row of a sheet
+func SheetOptionTotalRow(label string, style CellStyle) SheetOption {
+	return sheetOptionTotalRow{totalRow: GeneratorOptionTotalRow{Label: label, Style: style}}
+}
+
+// SheetOptionSanitizeStrings creates option escaping strings which would be read as formulas in a sheet
+func SheetOptionSanitizeStrings(enabled bool) SheetOption {
+	return sheetOptionSanitizeStrings{enabled: enabled}
+}
+
+// SheetOptionHidden creates option hiding or showing a sheet
+func SheetOptionHidden(hidden bool) SheetOption {
+	return sheetOptionHidden{enabled: hidden}
+}
+
+// SheetOptionColumnOrder creates option placing columns with given header names first in a sheet,
+// it replaces generator's column order
+func SheetOptionColumnOrder(names []string) SheetOption {
+	return sheetOptionColumnOrder{names: names}
+}
+
+// SheetOptionColumns creates option writing only columns with given tag names in a sheet, it replaces generator's columns
+func SheetOptionColumns(names []string) SheetOption {
+	return sheetOptionColumns{names: names}
+}
+
+// SheetOptionExcludeColumns creates option leaving out columns with given tag names in a sheet,
+// it replaces generator's excluded columns
+func SheetOptionExcludeColumns(names []string) SheetOption {
+	return sheetOptionExcludeColumns{names: names}
+}
+
+// SheetOptionHeaderNames creates option renaming headers of a sheet, names are merged with generator's header names
+func SheetOptionHeaderNames(values map[string]string) SheetOption {
+	return sheetOptionHeaderNames{values: values}
+}
+
+// SheetOptionCustomDropdown creates custom dropdown option of a sheet, values are merged with generator's custom dropdown
+func SheetOptionCustomDropdown(values map[string][]string) SheetOption {
+	return sheetOptionCustomDropdown{values: values}
+}
+
+// SheetOptionDependentDropdown creates dependent dropdown option of a sheet, values are merged with generator's dependent dropdown
+func SheetOptionDependentDropdown(values map[string]map[string][]string) SheetOption {
+	return sheetOptionDependentDropdown{values: values}
+}
+
+// SheetOptionLabeledDropdown creates labeled dropdown option of a sheet, items are merged with generator's labeled dropdown
+func SheetOptionLabeledDropdown(values map[string][]DropdownItem) SheetOption {
+	return sheetOptionLabeledDropdown{values: values}
+}
+
+// SheetOptionConditionalFormat creates conditional formatting option of a sheet, rules replace generator's rules
+// of the same column
+func SheetOptionConditionalFormat(values map[string][]ConditionalFormat) SheetOption {
+	return sheetOptionConditionalFormat{values: values}
+}
+
 // CustomOptions holds options for cells and cols
 type CustomOptions struct {
-	Format         string
+	Format         string // format:, note: and validation texts keep their commas up to the next known key
 	Width          float64
 	ColumnName     string
+	Header         string // Header is written to header cell instead of ColumnName, set by generator
 	Skip           bool
 	CustomDropdown CustomDropdown
+	Validation     Validation
 	Fill           string
+	Freeze         bool
+	Formula        string // formula:, cf-formula: and valid-formula: take the rest of tag, so they have to be the last key
+	Total          string
+	Link           Link
+	Note           string
+	Text           bool // Text writes values as text cells, so codes like 00123 and long numbers keep their digits
+	NoSanitize     bool // NoSanitize writes strings of column as they are, even with GeneratorOptionSanitizeStrings
+	Order          int  // Order places column before columns without it, columns with it are sorted by it
+	HeaderStyle    CellStyle
+	CellStyle      CellStyle
+	// ConditionalFormats are applied to data cells of column, rules from tag use ConditionalStyle
+	ConditionalFormats []ConditionalFormat
+	ConditionalStyle   CellStyle
+	TotalStyle         CellStyle
+	Styles             *StyleRegistry // Registry sharing styles of cells, nil creates a new style for every cell
 }
 
 type CustomDropdown struct {
 	Rows   int
 	Sheet  string
 	Values []string
+	// Depends is name of column which value selects list of dependent dropdown, values are taken from GeneratorOptionDependentDropdown
+	Depends string
+	// Formula is list source of dependent dropdown, set by generator
+	Formula string
+	// Labels holds labels of values of labeled dropdown, set by generator
+	Labels map[string]string
+	// Lookup is set by generator when Values are too long for inline list and are written to lookup sheet
+	Lookup *DropdownLookup
 }
 
 // NewCustomOptions creates CustomOptions from tag value
 func (g *Generator) NewCustomOptions(tagValue string) (*CustomOptions, error) {
+	return newCustomOptions(tagValue, g.customDropdown, g.styles)
+}
+
+// formulaKeys take the rest of tag as value, so formulas can hold commas. They have to be the last key of tag.
+var formulaKeys = []string{"formula", "cf-formula", "valid-formula"}
+
+// textKeys keep commas of their values, value ends before the next segment starting with a known key
+var textKeys = []string{"format", "note", "desc", "prompt", "prompt-title", "error", "error-title"}
+
+// newCustomOptions parses tag value, dropdown values are looked up in customDropdown by column name
+// and named styles are looked up in styles
+func newCustomOptions(tagValue string, customDropdown map[string][]string, styles *StyleRegistry) (*CustomOptions, error) {
 	if tagValue == "" || tagValue == "-" {
 		return &CustomOptions{
 			Skip: true,
@@ -68,44 +387,115 @@ func (g *Generator) NewCustomOptions(tagValue string) (*CustomOptions, error) {
 		ColumnName: values[0],
 	}
 
-	for k, v := range values {
-		if k > 0 {
-			var err error
-			if strings.Contains(v, "format:") {
-				options.Format = strings.TrimPrefix(v, "format:")
-			}
-
-			if strings.Contains(v, "fill:") {
-				options.Fill = strings.TrimPrefix(v, "fill:")
+	for i := 1; i < len(values); i++ {
+		key, value, _ := strings.Cut(values[i], ":")
+		switch {
+		case slices.Contains(formulaKeys, key):
+			// Formula takes the rest of tag, so its function arguments are not split
+			_, value, _ = strings.Cut(strings.Join(values[i:], ","), ":")
+		case slices.Contains(textKeys, key):
+			for ; i+1 < len(values) && !isTagKey(values[i+1]); i++ {
+				value += "," + values[i+1]
 			}
+		}
 
-			if strings.Contains(v, "width:") {
-				options.Width, err = strconv.ParseFloat(strings.TrimPrefix(v, "width:"), 64)
-				if err != nil {
-					return options, err
-				}
-			}
+		if _, err := options.parseKey(key, value, customDropdown); err != nil {
+			return options, err
+		}
+		if slices.Contains(formulaKeys, key) {
+			break
+		}
+	}
 
-			if strings.Contains(v, "dropdown:") {
-				options.CustomDropdown.Rows, err = strconv.Atoi(strings.TrimPrefix(v, "dropdown:"))
-				if err != nil {
-					return options, err
-				}
+	// A cell holds one data validation, so a rule cannot be combined with dropdown
+	if options.Validation.Type != "" && options.CustomDropdown.Rows > 0 {
+		return options, &ErrInvalidValidation{Key: "dropdown", Value: string(options.Validation.Type)}
+	}
 
-				vals, ok := g.customDropdown[options.ColumnName]
-				if ok {
-					options.CustomDropdown.Values = vals
-				}
-			}
+	for i, rule := range options.ConditionalFormats {
+		if rule.usesStyle() {
+			options.ConditionalFormats[i].Style = options.ConditionalStyle
+		}
+	}
 
-			if strings.Contains(v, "dropdown-sheet:") {
-				options.CustomDropdown.Sheet = strings.TrimPrefix(v, "dropdown-sheet:")
+	if styles != nil {
+		options.Styles = styles
+		for _, style := range []CellStyle{options.HeaderStyle, options.CellStyle, options.ConditionalStyle, options.TotalStyle} {
+			if _, err := styles.resolve(style); err != nil {
+				return options, err
 			}
 		}
 	}
+
 	return options, nil
 }
 
+// parseKey sets option of tag key to value, it tells if key is known. Unknown keys are ignored.
+func (co *CustomOptions) parseKey(key, value string, customDropdown map[string][]string) (bool, error) {
+	var err error
+	switch key {
+	case "freeze":
+		co.Freeze = true
+	case "format":
+		co.Format = value
+	case "formula":
+		co.Formula = value
+	case "total":
+		co.Total = value
+	case "link":
+		co.Link.Template = value
+	case "link-sheet":
+		co.Link.Sheet = value
+	case "link-column":
+		co.Link.Column = value
+	case "text":
+		co.Text = true
+	case "no-sanitize":
+		co.NoSanitize = true
+	case "note", "desc":
+		co.Note = value
+	case "fill":
+		co.Fill = value
+	case "width":
+		co.Width, err = strconv.ParseFloat(value, 64)
+	case "order":
+		co.Order, err = strconv.Atoi(value)
+	case "dropdown":
+		co.CustomDropdown.Rows, err = strconv.Atoi(value)
+
+		vals, ok := customDropdown[co.ColumnName]
+		if ok {
+			co.CustomDropdown.Values = vals
+		}
+	case "dropdown-sheet":
+		co.CustomDropdown.Sheet = value
+	case "dropdown-depends":
+		co.CustomDropdown.Depends = value
+	case "style":
+		// style: is a shorthand of header-style: like fill: is of header-fill:
+		co.HeaderStyle.Style = value
+	default:
+		if ok, vErr := co.Validation.parseValidation(key, value); ok {
+			return true, vErr
+		}
+		if rule, ok := parseConditionalFormat(key, value); ok {
+			co.ConditionalFormats = append(co.ConditionalFormats, rule)
+			return true, nil
+		}
+		return co.parseStyleKey(key, value)
+	}
+
+	return true, err
+}
+
+// isTagKey tells if tag segment starts with a known key
+func isTagKey(segment string) bool {
+	key, _, _ := strings.Cut(segment, ":")
+	ok, _ := new(CustomOptions).parseKey(key, "", nil)
+
+	return ok
+}
+
 var defaultWidth = 12.0
 
 // ApplyToCol applies options to column
@@ -117,14 +507,31 @@ func (co *CustomOptions) ApplyToCol(col *xlsx.Col) {
 	}
 }
 
+// header returns text of header cell
+func (co *CustomOptions) header() string {
+	if co.Header != "" {
+		return co.Header
+	}
+
+	return co.ColumnName
+}
+
 // ApplyToHeaderCell applies options to header's cell
 func (co *CustomOptions) ApplyToHeaderCell(cell *xlsx.Cell, colIndex int, customName string) error {
-	cell.SetValue(co.ColumnName)
+	cell.SetValue(co.header())
 	if co.CustomDropdown.Rows > 0 {
 		sheet := cell.Row.Sheet
 		dv := xlsx.NewDataValidation(1, colIndex, co.CustomDropdown.Rows+1, colIndex, true)
 
-		if len(co.CustomDropdown.Values) > 0 {
+		if co.CustomDropdown.Formula != "" {
+			dv.Type = "list"
+			dv.Formula1 = co.CustomDropdown.Formula
+		} else if lookup := co.CustomDropdown.Lookup; lookup != nil {
+			err := dv.SetInFileList(lookup.Sheet, lookup.Column, 0, lookup.Column, lookup.Rows-1)
+			if err != nil {
+				return err
+			}
+		} else if len(co.CustomDropdown.Values) > 0 {
 			err := dv.SetDropList(co.CustomDropdown.Values)
 			if err != nil {
 				return err
@@ -146,25 +553,37 @@ func (co *CustomOptions) ApplyToHeaderCell(cell *xlsx.Cell, colIndex int, custom
 			}
 		}
 
+		co.Validation.setMessages(dv)
 		sheet.AddDataValidation(dv)
-
-		return nil
 	}
+	co.Validation.addDataValidation(cell.Row.Sheet, colIndex)
 
-	if co.Fill != "" {
-		style := xlsx.NewStyle()
-		style.Fill.FgColor = co.Fill
-		style.Fill.PatternType = "solid"
-		style.ApplyFill = true
+	// fill: is kept as shorthand of header-fill:
+	headerStyle := co.HeaderStyle
+	if headerStyle.Fill == "" {
+		headerStyle.Fill = co.Fill
+	}
+	if style := styleOf(co.Styles, headerStyle); style != nil {
 		cell.SetStyle(style)
 	}
 
 	return nil
 }
 
+// textFormat is number format of text, values typed into such cells are kept as text
+const textFormat = "@"
+
 // ApplyToCell applies options to cell
 func (co *CustomOptions) ApplyToCell(cell *xlsx.Cell) {
-	if co.Format != "" {
-		cell.SetFormat(co.Format)
+	format := co.Format
+	if format == "" && co.Text {
+		format = textFormat
+	}
+	if format != "" {
+		cell.SetFormat(format)
+	}
+
+	if style := styleOf(co.Styles, co.CellStyle); style != nil {
+		cell.SetStyle(style)
 	}
 }
@@ -1,9 +1,11 @@
 package autoxlsx
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
 )
 
 func TestNewCustomOptions(t *testing.T) {
@@ -69,6 +71,119 @@ func TestNewCustomOptions(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "name and freeze",
+			arg:  "Some Name,freeze",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Freeze:     true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and header and cell styles",
+			arg:  "Some Name,fill:FF0000,header-bold,header-align:center,cell-font-size:9,cell-border:thin,cell-wrap,cell-indent:2",
+			want: &CustomOptions{
+				ColumnName:  "Some Name",
+				Fill:        "FF0000",
+				HeaderStyle: CellStyle{Bold: true, Align: "center"},
+				CellStyle:   CellStyle{FontSize: 9, Border: "thin", Wrap: true, Indent: 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and text",
+			arg:  "Some Name,text",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Text:       true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and data validation",
+			arg:  "Some Name,valid-date:2024-01-31:,prompt:Date of order,error-style:warning",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Validation: Validation{Type: ValidationDate, Min: "DATE(2024,1,31)", Prompt: "Date of order", ErrorStyle: "warning"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "name and invalid data validation date",
+			arg:     "Some Name,valid-date:31.01.2024",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "name and data validation with dropdown",
+			arg:     "Some Name,valid-whole:1:10,dropdown:5",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "name and formula with arguments",
+			arg:  "Some Name,width:10,formula:=ROUND(A{row}*2,2)",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Width:      10,
+				Formula:    "=ROUND(A{row}*2,2)",
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and conditional formula with arguments",
+			arg:  "Some Name,cf-formula:AND({cell}>1,{cell}<5)",
+			want: &CustomOptions{
+				ColumnName:         "Some Name",
+				ConditionalFormats: []ConditionalFormat{{Type: ConditionalFormatExpression, Formulas: []string{"AND({cell}>1,{cell}<5)"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and validation formula with arguments",
+			arg:  "Some Name,prompt:Even,valid-formula:=MOD({cell},2)=0",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Validation: Validation{Type: ValidationCustom, Formula: "=MOD({cell},2)=0", Prompt: "Even"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and format with thousands separator",
+			arg:  "Some Name,format:#,##0.00,width:10",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Format:     "#,##0.00",
+				Width:      10,
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and texts with commas",
+			arg:  "Some Name,note:Customer name, as on invoice,prompt:Name, surname,valid-rows:5,error:No, try again",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Note:       "Customer name, as on invoice",
+				Validation: Validation{Prompt: "Name, surname", Rows: 5, Error: "No, try again"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "name and unknown key",
+			arg:  "Some Name,formla:=A1,width:10",
+			want: &CustomOptions{
+				ColumnName: "Some Name",
+				Width:      10,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "name and invalid indent",
+			arg:     "Some Name,cell-indent:two",
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,3 +203,76 @@ func TestNewCustomOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomOptions_ApplyToCell(t *testing.T) {
+	options, err := newCustomOptions("value,format:0.00,cell-bold,cell-font-color:FF00FF00,cell-border:thin,cell-align:right,cell-wrap", nil, nil)
+	if err != nil {
+		t.Fatalf("newCustomOptions got err= %v", err)
+	}
+
+	sheet, err := xlsx.NewFile().AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	cell := sheet.AddRow().AddCell()
+	options.ApplyToCell(cell)
+
+	style := cell.GetStyle()
+	if diff := cmp.Diff("0.00", cell.NumFmt); diff != "" {
+		t.Errorf("cell format differs from expected (-want +got)\n%s", diff)
+	}
+	if !style.Font.Bold || style.Font.Color != "FF00FF00" || !style.ApplyFont {
+		t.Errorf("cell font got %+v, want bold FF00FF00", style.Font)
+	}
+	if diff := cmp.Diff(*xlsx.NewBorder("thin", "thin", "thin", "thin"), style.Border); diff != "" {
+		t.Errorf("cell border differs from expected (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(xlsx.Alignment{Horizontal: "right", Vertical: "bottom", WrapText: true}, style.Alignment); diff != "" {
+		t.Errorf("cell alignment differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestStyleRegistry(t *testing.T) {
+	registry := NewStyleRegistry()
+	registry.Define("header-red", CellStyle{Fill: "FFFF0000", Bold: true})
+
+	generator := NewGenerator(GeneratorOptionStyles(registry))
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+
+	type styledStruct struct {
+		ID    int `xlsx:"id,style:header-red,cell-border:thin"`
+		Value int `xlsx:"value,header-style:header-red,header-italic,cell-border:thin"`
+	}
+	err = generator.AddData(sheetNo, []styledStruct{{ID: 1, Value: 2}, {ID: 3, Value: 4}})
+	if err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+
+	sheet, _ := generator.GetSheet(sheetNo)
+	cell := func(col, row int) *xlsx.Cell {
+		c, err := sheet.Cell(row, col)
+		if err != nil {
+			t.Fatalf("Cell got err= %v", err)
+		}
+		return c
+	}
+
+	idHeader, valueHeader := cell(0, 0).GetStyle(), cell(1, 0).GetStyle()
+	if !idHeader.Font.Bold || idHeader.Fill.FgColor != "FFFF0000" {
+		t.Errorf("id header style got %+v, want named style", idHeader)
+	}
+	if !valueHeader.Font.Italic || !valueHeader.Font.Bold || valueHeader == idHeader {
+		t.Errorf("value header style got %+v, want named style with italic", valueHeader)
+	}
+	if first := cell(0, 1).GetStyle(); first != cell(1, 1).GetStyle() || first != cell(0, 2).GetStyle() {
+		t.Errorf("identical data cell styles are not shared")
+	}
+
+	_, err = generator.NewCustomOptions("id,cell-style:missing")
+	if !errors.As(err, new(*ErrStyleNotFound)) {
+		t.Errorf("NewCustomOptions got err= %v, want ErrStyleNotFound", err)
+	}
+}
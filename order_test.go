@@ -0,0 +1,201 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type OrderAudit struct {
+	CreatedBy string `xlsx:"created_by"`
+	Internal  string
+}
+
+type OrderStruct struct {
+	OrderAudit
+	Name  string         `xlsx:"name,order:2"`
+	ID    int            `xlsx:"id,order:1"`
+	Tags  map[string]int `xlsx:"tag"`
+	Price float64        `xlsx:"price"`
+	Total Formula        `xlsx:"total,formula:{price}*2"`
+}
+
+func TestGenerator_ColumnOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []GeneratorOption
+		sheet   []SheetOption
+		want    [][]string
+	}{
+		{
+			name: "order tags",
+			want: [][]string{
+				{"id", "name", "created_by", "x", "price", "total"},
+				{"7", "apple", "bob", "3", "1.5", "E2*2"},
+			},
+		},
+		{
+			name:    "column order option",
+			options: []GeneratorOption{GeneratorOptionColumnOrder([]string{"total", "x", "price", "unknown"})},
+			want: [][]string{
+				{"total", "x", "price", "id", "name", "created_by"},
+				{"C2*2", "3", "1.5", "7", "apple", "bob"},
+			},
+		},
+		{
+			name:    "sheet column order",
+			options: []GeneratorOption{GeneratorOptionColumnOrder([]string{"total"})},
+			sheet:   []SheetOption{SheetOptionColumnOrder([]string{"created_by"})},
+			want: [][]string{
+				{"created_by", "id", "name", "x", "price", "total"},
+				{"bob", "7", "apple", "3", "1.5", "E2*2"},
+			},
+		},
+	}
+
+	data := []OrderStruct{{OrderAudit: OrderAudit{CreatedBy: "bob"}, Name: "apple", ID: 7, Tags: map[string]int{"x": 3}, Price: 1.5}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.options...)
+			sheetNo, err := generator.AddSheet("sheet", tt.sheet...)
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			if err := generator.AddData(sheetNo, data); err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			var got [][]string
+			for row := range tt.want {
+				var values []string
+				for col := range tt.want[row] {
+					cell, err := sheet.Cell(row, col)
+					if err != nil {
+						t.Fatalf("Cell got err= %v", err)
+					}
+					value := cell.Value
+					if cell.Formula() != "" {
+						value = cell.Formula()
+					}
+					values = append(values, value)
+				}
+				got = append(got, values)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("cells differ from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStreamGenerator_ColumnOrder(t *testing.T) {
+	buff := new(bytes.Buffer)
+	s := NewStreamGenerator(buff, GeneratorOptionColumnOrder([]string{"price"}))
+	sheetNo, err := s.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+
+	data := []OrderStruct{
+		{OrderAudit: OrderAudit{CreatedBy: "bob"}, Name: "apple", ID: 7, Tags: map[string]int{"x": 3}, Price: 1.5},
+		{OrderAudit: OrderAudit{CreatedBy: "ann"}, Name: "pear", ID: 8, Tags: map[string]int{"x": 4}, Price: 2},
+	}
+	for _, batch := range [][]OrderStruct{data[:1], data[1:]} {
+		if err := s.WriteRows(sheetNo, batch); err != nil {
+			t.Fatalf("WriteRows got err= %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	var got []OrderStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("sheet differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+type OrderMapStruct struct {
+	A string            `xlsx:"a"`
+	M map[string]string `xlsx:"*,order:1"`
+	B string            `xlsx:"b"`
+}
+
+func TestUnmarshal_ColumnOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []GeneratorOption
+		unmarshal []UnmarshalOption
+	}{
+		{
+			name: "ordered map field",
+		},
+		{
+			name:      "column order option",
+			options:   []GeneratorOption{GeneratorOptionColumnOrder([]string{"b", "a"})},
+			unmarshal: []UnmarshalOption{UnmarshalOptionColumnOrder([]string{"b", "a"})},
+		},
+	}
+
+	data := []OrderMapStruct{{A: "1", M: map[string]string{"x": "2", "y": "3"}, B: "4"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.options...)
+			sheetNo, err := generator.AddSheet("sheet")
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			if err := generator.AddData(sheetNo, data); err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+			buff := new(bytes.Buffer)
+			if err := generator.SaveTo(buff); err != nil {
+				t.Fatalf("SaveTo got err= %v", err)
+			}
+
+			var got []OrderMapStruct
+			err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}),
+				tt.unmarshal...)
+			if err != nil {
+				t.Fatalf("Unmarshal got err= %v", err)
+			}
+			if diff := cmp.Diff(data, got); diff != "" {
+				t.Errorf("sheet differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_UnassignedColumns(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionColumnOrder([]string{"y"}))
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(sheetNo, []UnmarshalStruct{{ID: 1, Values: map[string]*float64{"x": &exampleFloat, "y": &exampleFloat}}}); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	var got []UnmarshalStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}))
+	var unassigned *ErrUnassignedColumns
+	if !errors.As(err, &unassigned) {
+		t.Fatalf("Unmarshal got err= %v, want ErrUnassignedColumns", err)
+	}
+	if diff := cmp.Diff([]string{"y"}, unassigned.Headers); diff != "" {
+		t.Errorf("unassigned headers differ from expected (-want +got)\n%s", diff)
+	}
+}
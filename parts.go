@@ -0,0 +1,130 @@
+package autoxlsx
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// contentTypesPart is name of part holding content types of other parts
+const contentTypesPart = "[Content_Types].xml"
+
+// worksheetTailElements are worksheet elements which follow conditionalFormatting, in order of the schema.
+// tealeg/xlsx writes autoFilter at the end of worksheet, so it's included as well.
+var worksheetTailElements = []string{
+	"<dataValidations", "<hyperlinks", "<printOptions", "<pageMargins", "<pageSetup", "<headerFooter",
+	"<drawing", "<legacyDrawing", "<tableParts", "<extLst", "<autoFilter", "</worksheet>",
+}
+
+// patchParts adds to parts written by tealeg/xlsx features which it does not support
+func (g *Generator) patchParts(parts map[string]string) error {
+	var dxfs styleTable
+	shapeBlock := 1
+	for sheetNo, settings := range g.settings {
+		name := fmt.Sprintf("xl/worksheets/sheet%d.xml", sheetNo+1)
+		// tealeg/xlsx writes empty relationship id for links to locations in the workbook
+		parts[name] = strings.ReplaceAll(parts[name], ` r:id=""`, "")
+
+		formatting, err := conditionalFormattingXML(settings.conditionalColumns, settings.lastRow, g.dxfID(&dxfs))
+		if err != nil {
+			return err
+		}
+
+		if formatting != "" {
+			parts[name] = insertBefore(parts[name], formatting, worksheetTailElements)
+		}
+
+		if len(settings.comments) > 0 {
+			shapeBlock = patchComments(parts, sheetNo, settings.comments, shapeBlock)
+		}
+
+		if settings.veryHidden {
+			// tealeg/xlsx writes only hidden state, very hidden sheets cannot be shown from Excel UI
+			sheetElement := fmt.Sprintf(`r:id="rId%d" state="hidden"`, sheetNo+1)
+			parts["xl/workbook.xml"] = strings.Replace(parts["xl/workbook.xml"], sheetElement, strings.Replace(sheetElement, "hidden", "veryHidden", 1), 1)
+		}
+	}
+
+	if len(dxfs.items) > 0 {
+		parts["xl/styles.xml"] = insertBefore(parts["xl/styles.xml"], dxfsXML(dxfs), []string{"<tableStyles", "<colors", "<extLst", "</styleSheet>"})
+	}
+
+	return nil
+}
+
+// patchComments adds comments of sheet with their legacy drawing to parts, shape ids of drawing start
+// with shapeBlock. The next free block is returned.
+func patchComments(parts map[string]string, sheetNo int, comments []cellComment, shapeBlock int) int {
+	parts[fmt.Sprintf(commentsPartFmt, sheetNo+1)] = commentsXML(comments)
+	parts[fmt.Sprintf(vmlDrawingPartFmt, sheetNo+1)], shapeBlock = vmlDrawingXML(shapeBlock, comments)
+
+	name := fmt.Sprintf("xl/worksheets/sheet%d.xml", sheetNo+1)
+	parts[name] = insertBefore(parts[name], legacyDrawingElement, []string{"<legacyDrawingHF", "<tableParts", "<extLst", "<autoFilter", "</worksheet>"})
+
+	rels := fmt.Sprintf(streamSheetRelsPartFmt, sheetNo+1)
+	if _, ok := parts[rels]; !ok {
+		parts[rels] = xmlHeader + `<Relationships xmlns="` + packageRelationshipsNS + `"></Relationships>`
+	}
+	parts[rels] = insertBefore(parts[rels], commentsRelationships(sheetNo), []string{"</Relationships>"})
+
+	contentTypes := commentsContentTypeOverride(sheetNo)
+	if !strings.Contains(parts[contentTypesPart], `Extension="vml"`) {
+		contentTypes += vmlDrawingExtension
+	}
+	parts[contentTypesPart] = insertBefore(parts[contentTypesPart], contentTypes, []string{"</Types>"})
+
+	return shapeBlock
+}
+
+// dxfID returns function adding differential styles to dxfs
+func (g *Generator) dxfID(dxfs *styleTable) func(CellStyle) (int, error) {
+	return func(style CellStyle) (int, error) {
+		style, err := g.styles.resolve(style)
+		if err != nil {
+			return 0, err
+		}
+
+		return dxfs.add(dxfXML(style)), nil
+	}
+}
+
+// dxfsXML returns dxfs element of styles.xml
+func dxfsXML(dxfs styleTable) string {
+	return fmt.Sprintf(`<dxfs count="%d">%s</dxfs>`, len(dxfs.items), strings.Join(dxfs.items, ""))
+}
+
+// insertBefore inserts element into part before the first found of following elements
+func insertBefore(part, element string, following []string) string {
+	for _, tag := range following {
+		if i := strings.Index(part, tag); i >= 0 {
+			return part[:i] + element + part[i:]
+		}
+	}
+
+	return part + element
+}
+
+// writeParts writes parts as zip archive to out
+func writeParts(out io.Writer, parts map[string]string) error {
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	// [Content_Types].xml is sorted first
+	slices.Sort(names)
+
+	zw := zip.NewWriter(out)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, parts[name]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -1,15 +1,121 @@
 package helpers
 
 import (
+	"database/sql/driver"
+	"math"
+	"math/big"
 	"reflect"
-	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
-var commonGoStructs = []reflect.Type{
-	reflect.TypeOf(time.Time{}),
+// LeafType describes type written as a single cell value. Value converts value of the type to one accepted
+// by cell (nil gives blank cell), Format is number format used when column has none. Types registered
+// without Value are written with their encoding.TextMarshaler or fmt.Stringer.
+type LeafType struct {
+	Value  func(v reflect.Value) interface{}
+	Format string
 }
 
+var (
+	leafTypesMu sync.RWMutex
+	leafTypes   = map[reflect.Type]LeafType{
+		reflect.TypeOf(time.Time{}):      {Value: reflect.Value.Interface},
+		reflect.TypeOf(time.Duration(0)): {Value: durationValue, Format: "[h]:mm:ss"},
+		reflect.TypeOf(big.Int{}):        {Value: bigIntValue},
+		reflect.TypeOf(big.Float{}):      {Value: bigFloatValue},
+	}
+)
+
+// MaxExactInt is the greatest integer which Excel keeps without losing digits, it keeps 15 significant digits
+const MaxExactInt = 999_999_999_999_999
+
+// RegisterLeafType registers t as leaf type, so it's written as a single cell instead of columns of its fields
+func RegisterLeafType(t reflect.Type, leaf LeafType) {
+	leafTypesMu.Lock()
+	defer leafTypesMu.Unlock()
+
+	leafTypes[t] = leaf
+}
+
+// GetLeafType returns leaf type registered for t. Null types of database/sql are leaf types
+// without registration, their invalid values give blank cells.
+func GetLeafType(t reflect.Type) (LeafType, bool) {
+	leafTypesMu.RLock()
+	leaf, ok := leafTypes[t]
+	leafTypesMu.RUnlock()
+	if ok {
+		return leaf, true
+	}
+
+	if isSQLNullType(t) {
+		return LeafType{Value: sqlNullValue}, true
+	}
+
+	return LeafType{}, false
+}
+
+// IsCommonGoStruct reports whether struct type t is a leaf type
 func IsCommonGoStruct(t reflect.Type) bool {
-	return slices.Contains(commonGoStructs, t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := GetLeafType(t)
+
+	return ok
+}
+
+var valuerType = reflect.TypeFor[driver.Valuer]()
+
+// isSQLNullType reports whether t is one of sql.NullString, sql.NullInt64, sql.Null[T] etc.
+func isSQLNullType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") &&
+		t.Implements(valuerType)
+}
+
+// sqlNullValue returns value of sql Null type, nil when it's not valid
+func sqlNullValue(v reflect.Value) interface{} {
+	value, err := v.Interface().(driver.Valuer).Value()
+	if err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// durationValue returns duration as Excel time, which is a fraction of a day
+func durationValue(v reflect.Value) interface{} {
+	return time.Duration(v.Int()).Hours() / 24
+}
+
+// bigIntValue returns number when it fits Excel precision, text otherwise
+func bigIntValue(v reflect.Value) interface{} {
+	x := addressable(v).Addr().Interface().(*big.Int)
+	if x.IsInt64() && x.Int64() <= MaxExactInt && x.Int64() >= -MaxExactInt {
+		return x.Int64()
+	}
+
+	return x.String()
+}
+
+// bigFloatValue returns number when it fits float64 exactly, text otherwise
+func bigFloatValue(v reflect.Value) interface{} {
+	x := addressable(v).Addr().Interface().(*big.Float)
+	if f, accuracy := x.Float64(); accuracy == big.Exact && !math.IsInf(f, 0) {
+		return f
+	}
+
+	return x.Text('g', -1)
+}
+
+// addressable returns v or its addressable copy
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+
+	return c
 }
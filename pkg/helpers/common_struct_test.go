@@ -1,6 +1,8 @@
 package helpers_test
 
 import (
+	"database/sql"
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
@@ -21,6 +23,26 @@ func TestIsCommonGoStruct(t *testing.T) {
 			input:    reflect.TypeOf(time.Time{}),
 			expected: true,
 		},
+		{
+			name:     "SQLNullType",
+			input:    reflect.TypeOf(sql.NullString{}),
+			expected: true,
+		},
+		{
+			name:     "GenericSQLNullType",
+			input:    reflect.TypeOf(sql.Null[int]{}),
+			expected: true,
+		},
+		{
+			name:     "BigInt",
+			input:    reflect.TypeOf(big.Int{}),
+			expected: true,
+		},
+		{
+			name:     "Duration",
+			input:    reflect.TypeOf(time.Duration(0)),
+			expected: false,
+		},
 		{
 			name:     "NonCommonStruct",
 			input:    reflect.TypeOf(struct{ Field int }{}),
@@ -38,3 +60,47 @@ func TestIsCommonGoStruct(t *testing.T) {
 		})
 	}
 }
+
+type registeredLeaf struct {
+	Amount int
+}
+
+func TestGetLeafType(t *testing.T) {
+	helpers.RegisterLeafType(reflect.TypeOf(registeredLeaf{}), helpers.LeafType{
+		Value:  func(v reflect.Value) interface{} { return float64(v.Field(0).Int()) / 100 },
+		Format: "0.00",
+	})
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	testCases := []struct {
+		name       string
+		input      interface{}
+		expected   interface{}
+		wantFormat string
+	}{
+		{name: "ValidNullString", input: sql.NullString{String: "a", Valid: true}, expected: "a"},
+		{name: "InvalidNullInt64", input: sql.NullInt64{Int64: 5}, expected: nil},
+		{name: "GenericNull", input: sql.Null[int64]{V: 7, Valid: true}, expected: int64(7)},
+		{name: "Duration", input: 36 * time.Hour, expected: 1.5, wantFormat: "[h]:mm:ss"},
+		{name: "SmallBigInt", input: *big.NewInt(42), expected: int64(42)},
+		{name: "HugeBigInt", input: *huge, expected: "123456789012345678901234567890"},
+		{name: "BigFloat", input: *big.NewFloat(0.5), expected: 0.5},
+		{name: "RegisteredLeaf", input: registeredLeaf{Amount: 1250}, expected: 12.5, wantFormat: "0.00"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			leaf, ok := helpers.GetLeafType(reflect.TypeOf(testCase.input))
+			if !ok {
+				t.Fatalf("GetLeafType got ok= %v, want true", ok)
+			}
+
+			if diff := cmp.Diff(testCase.expected, leaf.Value(reflect.ValueOf(testCase.input))); diff != "" {
+				t.Errorf("GetLeafType value differs from expected (-want +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.wantFormat, leaf.Format); diff != "" {
+				t.Errorf("GetLeafType format differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -51,3 +51,63 @@ func AreAllMapKeysSame(fields []reflect.Value) (bool, error) {
 
 	return true, nil
 }
+
+// MapField identifies map field of a struct type.
+type MapField struct {
+	Owner reflect.Type
+	Name  string
+}
+
+// CollectMapKeys adds keys of every map field of value v (of struct type t), including fields of nested structs,
+// to keys. Keys of each field are kept sorted and unique.
+func CollectMapKeys(keys map[MapField][]string, t reflect.Type, v reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ft := field.Type
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				fv = fv.Elem()
+			}
+		}
+
+		switch {
+		case ft.Kind() == reflect.Map:
+			if !fv.IsValid() {
+				continue
+			}
+			mapField := MapField{Owner: t, Name: field.Name}
+			for _, key := range fv.MapKeys() {
+				if idx, found := slices.BinarySearch(keys[mapField], key.String()); !found {
+					keys[mapField] = slices.Insert(keys[mapField], idx, key.String())
+				}
+			}
+		case ft.Kind() == reflect.Struct && !IsCommonGoStruct(ft):
+			CollectMapKeys(keys, ft, fv)
+		}
+	}
+}
+
+// HasMapFields checks if struct type t or any of its nested structs has a map field.
+func HasMapFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Map {
+			return true
+		}
+		if ft.Kind() == reflect.Struct && !IsCommonGoStruct(ft) && HasMapFields(ft) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -84,3 +84,37 @@ func TestAreAllMapKeysSame(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectMapKeys(t *testing.T) {
+	type nested struct {
+		Values map[string]int
+	}
+	type item struct {
+		Labels map[string]string
+		Nested *nested
+	}
+
+	keys := make(map[helpers.MapField][]string)
+	items := []item{
+		{Labels: map[string]string{"b": "1"}},
+		{Labels: map[string]string{"a": "1", "b": "2"}, Nested: &nested{Values: map[string]int{"z": 1}}},
+	}
+	for _, it := range items {
+		helpers.CollectMapKeys(keys, reflect.TypeOf(it), reflect.ValueOf(it))
+	}
+
+	expected := map[helpers.MapField][]string{
+		{Owner: reflect.TypeOf(item{}), Name: "Labels"}:   {"a", "b"},
+		{Owner: reflect.TypeOf(nested{}), Name: "Values"}: {"z"},
+	}
+	if diff := cmp.Diff(expected, keys); diff != "" {
+		t.Errorf("CollectMapKeys keys differ from expected (-want +got)\n%s", diff)
+	}
+
+	if !helpers.HasMapFields(reflect.TypeOf(item{})) {
+		t.Errorf("HasMapFields got false, want true")
+	}
+	if helpers.HasMapFields(reflect.TypeOf(struct{ ID int }{})) {
+		t.Errorf("HasMapFields got true, want false")
+	}
+}
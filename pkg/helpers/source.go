@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"reflect"
+)
+
+// SourceElemType returns element type of data source type t. Supported sources are slices, iter.Seq[T],
+// iter.Seq2[int, T] and channels which can be received from. The second value is false for unsupported types.
+func SourceElemType(t reflect.Type) (reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem(), true
+	case reflect.Chan:
+		if t.ChanDir()&reflect.RecvDir == 0 {
+			return nil, false
+		}
+		return t.Elem(), true
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 {
+			return nil, false
+		}
+		yield := t.In(0)
+		if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+			return nil, false
+		}
+		switch {
+		case yield.NumIn() == 1:
+			return yield.In(0), true
+		case yield.NumIn() == 2 && yield.In(0).Kind() == reflect.Int:
+			return yield.In(1), true
+		}
+	}
+
+	return nil, false
+}
+
+// ForEachSourceItem calls fn for every element of data source (see SourceElemType), iteration stops on first error.
+// Channels are consumed until they are closed, nil channels and functions are treated as empty sources.
+func ForEachSourceItem(source reflect.Value, fn func(item reflect.Value) error) error {
+	if (source.Kind() == reflect.Func || source.Kind() == reflect.Chan) && source.IsNil() {
+		return nil
+	}
+
+	var err error
+	yield := func(item reflect.Value) bool {
+		err = fn(item)
+		return err == nil
+	}
+
+	switch source.Kind() {
+	case reflect.Slice:
+		for i := 0; i < source.Len(); i++ {
+			if !yield(source.Index(i)) {
+				break
+			}
+		}
+	case reflect.Func:
+		if source.Type().In(0).NumIn() == 2 {
+			for _, item := range source.Seq2() {
+				if !yield(item) {
+					break
+				}
+			}
+			break
+		}
+		for item := range source.Seq() {
+			if !yield(item) {
+				break
+			}
+		}
+	default:
+		for item := range source.Seq() {
+			if !yield(item) {
+				break
+			}
+		}
+	}
+
+	return err
+}
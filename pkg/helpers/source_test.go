@@ -0,0 +1,143 @@
+package helpers_test
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/arturwwl/autoxlsx/pkg/helpers"
+)
+
+func TestSourceElemType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected reflect.Type
+		ok       bool
+	}{
+		{
+			name:     "Slice",
+			input:    []int{},
+			expected: reflect.TypeOf(0),
+			ok:       true,
+		},
+		{
+			name:     "Seq",
+			input:    iter.Seq[string](nil),
+			expected: reflect.TypeOf(""),
+			ok:       true,
+		},
+		{
+			name:     "Seq2",
+			input:    iter.Seq2[int, string](nil),
+			expected: reflect.TypeOf(""),
+			ok:       true,
+		},
+		{
+			name:     "Receive Channel",
+			input:    make(<-chan float64),
+			expected: reflect.TypeOf(0.0),
+			ok:       true,
+		},
+		{
+			name:  "Send Channel",
+			input: make(chan<- float64),
+			ok:    false,
+		},
+		{
+			name:  "Seq2 With String Keys",
+			input: iter.Seq2[string, string](nil),
+			ok:    false,
+		},
+		{
+			name:  "Struct",
+			input: struct{}{},
+			ok:    false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, ok := helpers.SourceElemType(reflect.TypeOf(testCase.input))
+
+			if diff := cmp.Diff(testCase.ok, ok); diff != "" {
+				t.Errorf("SourceElemType ok value differs from expected (-want +got)\n%s", diff)
+			}
+			if result != testCase.expected {
+				t.Errorf("SourceElemType got %v, want %v", result, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestForEachSourceItem(t *testing.T) {
+	ch := make(chan string, 2)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected []string
+	}{
+		{
+			name:     "Slice",
+			input:    []string{"a", "b"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Seq",
+			input:    slices.Values([]string{"a", "b"}),
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Seq2",
+			input:    slices.All([]string{"a", "b"}),
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Channel",
+			input:    ch,
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Nil Seq",
+			input:    iter.Seq[string](nil),
+			expected: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var result []string
+			err := helpers.ForEachSourceItem(reflect.ValueOf(testCase.input), func(item reflect.Value) error {
+				result = append(result, item.String())
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, result); diff != "" {
+				t.Errorf("ForEachSourceItem items differ from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Stop On Error", func(t *testing.T) {
+		stop := errors.New("stop")
+		var count int
+		err := helpers.ForEachSourceItem(reflect.ValueOf(slices.Values([]int{1, 2, 3})), func(item reflect.Value) error {
+			count++
+			return stop
+		})
+		if !errors.Is(err, stop) || count != 1 {
+			t.Errorf("ForEachSourceItem got err= %v after %d items, want %v after 1 item", err, count, stop)
+		}
+	})
+}
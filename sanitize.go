@@ -0,0 +1,29 @@
+package autoxlsx
+
+import (
+	"strings"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// formulaPrefixes start strings which spreadsheet applications read as formulas
+const formulaPrefixes = "=+-@\t\r"
+
+// sanitizeCell prefixes string value of cell starting with formula character with apostrophe,
+// so the value is shown as it is and it's not evaluated
+func sanitizeCell(cell *xlsx.Cell) {
+	if cell.Type() != xlsx.CellTypeString || cell.Value == "" || !strings.ContainsRune(formulaPrefixes, rune(cell.Value[0])) {
+		return
+	}
+
+	cell.SetString("'" + cell.Value)
+}
+
+// unsanitize removes apostrophe added by sanitizeCell from value
+func unsanitize(value string) string {
+	if len(value) < 2 || value[0] != '\'' || !strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value
+	}
+
+	return value[1:]
+}
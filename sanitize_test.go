@@ -0,0 +1,127 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+)
+
+type SanitizeStruct struct {
+	Name    string  `xlsx:"name"`
+	Comment string  `xlsx:"comment"`
+	Number  int     `xlsx:"number"`
+	Total   Formula `xlsx:"total"`
+	Raw     string  `xlsx:"raw,no-sanitize"`
+}
+
+func TestGenerator_SanitizeStrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []GeneratorOption
+		sheet   []SheetOption
+		want    []string
+	}{
+		{
+			name:    "safe mode",
+			options: []GeneratorOption{GeneratorOptionSafeMode{}},
+			want:    []string{"'=HYPERLINK(\"http://x\")", "'@SUM(A1)", "-5", "B2*2", "=raw"},
+		},
+		{
+			name:    "sanitize strings disabled for sheet",
+			options: []GeneratorOption{GeneratorOptionSanitizeStrings{}},
+			sheet:   []SheetOption{SheetOptionSanitizeStrings(false)},
+			want:    []string{"=HYPERLINK(\"http://x\")", "@SUM(A1)", "-5", "B2*2", "=raw"},
+		},
+		{
+			name:  "sanitize strings enabled for sheet",
+			sheet: []SheetOption{SheetOptionSanitizeStrings(true)},
+			want:  []string{"'=HYPERLINK(\"http://x\")", "'@SUM(A1)", "-5", "B2*2", "=raw"},
+		},
+	}
+
+	data := []SanitizeStruct{{Name: `=HYPERLINK("http://x")`, Comment: "@SUM(A1)", Number: -5, Total: "=B2*2", Raw: "=raw"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.options...)
+			sheetNo, err := generator.AddSheet("sheet", tt.sheet...)
+			if err != nil {
+				t.Fatalf("AddSheet got err= %v", err)
+			}
+			if err := generator.AddData(sheetNo, data); err != nil {
+				t.Fatalf("AddData got err= %v", err)
+			}
+
+			sheet, _ := generator.GetSheet(sheetNo)
+			var got []string
+			for col := range tt.want {
+				cell, err := sheet.Cell(1, col)
+				if err != nil {
+					t.Fatalf("Cell got err= %v", err)
+				}
+				value := cell.Value
+				if cell.Formula() != "" {
+					value = cell.Formula()
+				}
+				got = append(got, value)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("sanitized cells differ from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_SanitizedStrings(t *testing.T) {
+	type row struct {
+		Name    string `xlsx:"name"`
+		Comment string `xlsx:"comment"`
+		Raw     string `xlsx:"raw,no-sanitize"`
+	}
+	data := []row{{Name: `=HYPERLINK("http://x")`, Comment: "'quoted", Raw: "-raw"}, {Name: "+1", Comment: "@SUM(A1)", Raw: "plain"}}
+
+	generated := new(bytes.Buffer)
+	generator := NewGenerator(GeneratorOptionSafeMode{})
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(sheetNo, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	if err := generator.SaveTo(generated); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	streamed := new(bytes.Buffer)
+	s := NewStreamGenerator(streamed, GeneratorOptionSanitizeStrings{})
+	if sheetNo, err = s.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(sheetNo, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	for name, buff := range map[string]*bytes.Buffer{"generator": generated, "stream": streamed} {
+		wb, err := xlsx.OpenBinary(buff.Bytes())
+		if err != nil {
+			t.Fatalf("%s: OpenBinary got err= %v", name, err)
+		}
+		want := [][]string{{"name", "comment", "raw"}, {`'=HYPERLINK("http://x")`, "'quoted", "-raw"}, {"'+1", "'@SUM(A1)", "plain"}}
+		if diff := cmp.Diff(want, sheetValues(t, wb.Sheet["sheet"])); diff != "" {
+			t.Errorf("%s: sanitized cells differ from expected (-want +got)\n%s", name, diff)
+		}
+
+		var got []row
+		if err := UnmarshalSheet(wb.Sheet["sheet"], &got); err != nil {
+			t.Fatalf("%s: UnmarshalSheet got err= %v", name, err)
+		}
+		if diff := cmp.Diff(data, got); diff != "" {
+			t.Errorf("%s: UnmarshalSheet return value differs from expected (-want +got)\n%s", name, diff)
+		}
+	}
+}
@@ -12,11 +12,13 @@ type Option func(*List)
 
 // List represents a list of sheets with associated data.
 type List struct {
-	list map[string]interface{} // The underlying map to store sheet data.
-	keys []string
+	list         map[string]interface{} // The underlying map to store sheet data.
+	keys         []string
+	sheetOptions map[string][]interface{} // Options of single sheets, passed to the generator.
 }
 
 // New creates a new List instance with the provided map of sheet data and applies options.
+// Sheet data can be a slice, iter.Seq[T], iter.Seq2[int, T] or a channel of structs.
 func New(m map[string]interface{}, options ...Option) *List {
 	l := &List{
 		list: m,
@@ -44,6 +46,16 @@ func WithSort(sortAsc bool) Option {
 	}
 }
 
+// WithSheetOptions is an option to attach sheet options (autoxlsx.SheetOption) to the sheet with given name.
+func WithSheetOptions(sheetName string, options ...interface{}) Option {
+	return func(l *List) {
+		if l.sheetOptions == nil {
+			l.sheetOptions = make(map[string][]interface{})
+		}
+		l.sheetOptions[sheetName] = append(l.sheetOptions[sheetName], options...)
+	}
+}
+
 // sortList sorts the list based on the provided sortAsc flag.
 // If sortAsc is true, the list is sorted in ascending order.
 // If sortAsc is false, the list is sorted in descending order.
@@ -58,3 +70,8 @@ func (l *List) sortList(sortAsc bool) {
 func (l *List) Get() (map[string]interface{}, []string) {
 	return l.list, l.keys
 }
+
+// SheetOptions retrieves options attached to the sheet with given name.
+func (l *List) SheetOptions(sheetName string) []interface{} {
+	return l.sheetOptions[sheetName]
+}
@@ -0,0 +1,569 @@
+package autoxlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+const (
+	xmlHeader          = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	spreadsheetMLNS    = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	relationshipsNS    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	worksheetRelType   = relationshipsNS + "/worksheet"
+	worksheetPartType  = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+	streamSheetPartFmt = "xl/worksheets/sheet%d.xml"
+
+	streamSheetRelsPartFmt = "xl/worksheets/_rels/sheet%d.xml.rels"
+	packageRelationshipsNS = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// StreamGenerator writes xlsx directly to io.Writer. Rows are written to the output as soon as they are added,
+// so only the current batch is kept in memory. Sheets are written one after another, once rows of the next
+// sheet are written, previous sheet is finished and no more rows can be added to it.
+type StreamGenerator struct {
+	sync.Mutex
+	g      *Generator
+	zw     *zip.Writer
+	sheets []*streamSheet
+	open   *streamSheet
+	styles *streamStyles
+	closed bool
+	// shapeBlock is the first block of shape ids not used by comments of finished sheets
+	shapeBlock int
+}
+
+// streamSheet holds state of a sheet written by StreamGenerator
+type streamSheet struct {
+	no        int
+	name      string
+	hidden    bool
+	w         *bufio.Writer
+	meta      *xlsx.Sheet // holds data validations and auto filter written when sheet is finished
+	started   bool
+	finished  bool
+	rows      int
+	rowLength int
+	links     []streamLink
+	comments  bool
+}
+
+// streamLink holds hyperlink of cell ref, written when sheet is finished
+type streamLink struct {
+	ref  string
+	link xlsx.Hyperlink
+}
+
+// NewStreamGenerator creates new stream generator writing to w, generator options have the same meaning as for NewGenerator
+func NewStreamGenerator(w io.Writer, options ...GeneratorOption) *StreamGenerator {
+	return &StreamGenerator{
+		Mutex:      sync.Mutex{},
+		g:          NewGenerator(options...),
+		zw:         zip.NewWriter(w),
+		styles:     newStreamStyles(),
+		shapeBlock: 1,
+	}
+}
+
+// AddSheet creates new sheet, sheet options override generator options for this sheet only
+func (s *StreamGenerator) AddSheet(sheetName string, options ...SheetOption) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return -1, &ErrStreamClosed{}
+	}
+
+	sheetNo, err := s.g.AddSheet(sheetName, options...)
+	if err != nil {
+		return -1, err
+	}
+	sheet, err := s.g.GetSheet(sheetNo)
+	if err != nil {
+		return -1, err
+	}
+
+	meta, err := xlsx.NewSheet(sheetName)
+	if err != nil {
+		return -1, err
+	}
+	s.sheets = append(s.sheets, &streamSheet{
+		no:     sheetNo,
+		name:   sheetName,
+		hidden: sheet.Hidden,
+		meta:   meta,
+	})
+
+	return sheetNo, s.resetSheet(sheetNo)
+}
+
+// WriteRows writes batch to sheet, headers are written with the first batch. Batch can be any data source accepted by Generator.AddData.
+// Map field columns are created for keys found in the first batch, further batches return ErrInconsistentMapKeys for new keys.
+func (s *StreamGenerator) WriteRows(sheetNo int, batch interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return &ErrStreamClosed{}
+	}
+	if len(s.sheets) <= sheetNo {
+		return &ErrSheetNotFound{}
+	}
+	ss := s.sheets[sheetNo]
+	if ss.finished {
+		return &ErrSheetFinished{}
+	}
+
+	if err := validateSource(batch); err != nil {
+		return err
+	}
+
+	// Formulas reference rows of the whole sheet, not of the current batch
+	s.g.settings[sheetNo].rowOffset = ss.rows
+	rowLength, _, err := s.g.processData(sheetNo, batch, !ss.started)
+	if lookup := s.g.lookup; lookup != nil && lookup.sheetNo == len(s.sheets) {
+		// Lookup sheet is created by headers of the batch, its rows are written on Close
+		meta, metaErr := xlsx.NewSheet(lookupSheetName)
+		if metaErr != nil {
+			return metaErr
+		}
+		s.sheets = append(s.sheets, &streamSheet{no: lookup.sheetNo, name: lookupSheetName, hidden: true, meta: meta})
+	}
+	if err != nil {
+		return err
+	}
+
+	sheet, err := s.g.GetSheet(sheetNo)
+	if err != nil {
+		return err
+	}
+
+	if !ss.started {
+		ss.rowLength = rowLength
+		if err := s.startSheet(ss, sheet); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeSheetRows(ss, sheet); err != nil {
+		return err
+	}
+	ss.meta.DataValidations = append(ss.meta.DataValidations, sheet.DataValidations...)
+
+	return s.resetSheet(sheetNo)
+}
+
+// Close finishes every sheet and writes remaining workbook parts, it does not close underlying writer
+func (s *StreamGenerator) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return &ErrStreamClosed{}
+	}
+	s.closed = true
+
+	if len(s.sheets) == 0 {
+		return fmt.Errorf("workbook must contain at least one sheet")
+	}
+
+	for _, ss := range s.sheets {
+		if ss.finished {
+			continue
+		}
+		if !ss.started {
+			sheet, err := s.g.GetSheet(ss.no)
+			if err != nil {
+				return err
+			}
+			if err := s.startSheet(ss, sheet); err != nil {
+				return err
+			}
+			if err := s.writeSheetRows(ss, sheet); err != nil {
+				return err
+			}
+			// Written rows are dropped so finishSheet writes only the totals row
+			if err := s.resetSheet(ss.no); err != nil {
+				return err
+			}
+		}
+		if err := s.finishSheet(ss); err != nil {
+			return err
+		}
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", s.contentTypes()},
+		{"_rels/.rels", xlsx.TEMPLATE__RELS_DOT_RELS},
+		{"docProps/app.xml", xlsx.TEMPLATE_DOCPROPS_APP},
+		{"docProps/core.xml", xlsx.TEMPLATE_DOCPROPS_CORE},
+		{"xl/workbook.xml", s.workbook()},
+		{"xl/_rels/workbook.xml.rels", s.workbookRels()},
+		{"xl/styles.xml", s.styles.marshal()},
+		{"xl/theme/theme1.xml", xlsx.TEMPLATE_XL_THEME_THEME},
+	}
+	for _, part := range parts {
+		if err := s.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	return s.zw.Close()
+}
+
+// writePart adds part with content to the archive
+func (s *StreamGenerator) writePart(name, content string) error {
+	w, err := s.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+
+	return err
+}
+
+// resetSheet replaces generator's sheet with an empty one, so rows already written can be released
+func (s *StreamGenerator) resetSheet(sheetNo int) error {
+	sheet, err := xlsx.NewSheet(s.sheets[sheetNo].name)
+	if err != nil {
+		return err
+	}
+
+	s.g.Mutex.Lock()
+	defer s.g.Mutex.Unlock()
+	s.g.sheets[sheetNo] = sheet
+
+	return nil
+}
+
+// startSheet finishes currently open sheet and writes beginning of ss with its views and columns
+func (s *StreamGenerator) startSheet(ss *streamSheet, sheet *xlsx.Sheet) error {
+	if s.open != nil {
+		if err := s.finishSheet(s.open); err != nil {
+			return err
+		}
+	}
+
+	w, err := s.zw.Create(fmt.Sprintf(streamSheetPartFmt, ss.no+1))
+	if err != nil {
+		return err
+	}
+	ss.w = bufio.NewWriter(w)
+	ss.started = true
+	s.open = ss
+
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	fmt.Fprintf(&b, `<worksheet xmlns="%s" xmlns:r="%s">`, spreadsheetMLNS, relationshipsNS)
+
+	s.g.setSheetViews(ss.no, sheet)
+	if len(sheet.SheetViews) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+		for _, view := range sheet.SheetViews {
+			if view.Pane == nil {
+				continue
+			}
+			fmt.Fprintf(&b, `<pane xSplit="%g" ySplit="%g" topLeftCell="%s" activePane="%s" state="%s"/>`,
+				view.Pane.XSplit, view.Pane.YSplit, view.Pane.TopLeftCell, view.Pane.ActivePane, view.Pane.State)
+		}
+		b.WriteString(`</sheetView></sheetViews>`)
+	}
+
+	var cols strings.Builder
+	sheet.Cols.ForEach(func(_ int, col *xlsx.Col) {
+		if col.Width == nil {
+			return
+		}
+		fmt.Fprintf(&cols, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, col.Min, col.Max, *col.Width)
+	})
+	if cols.Len() > 0 {
+		b.WriteString("<cols>" + cols.String() + "</cols>")
+	}
+	b.WriteString("<sheetData>")
+
+	_, err = ss.w.WriteString(b.String())
+	return err
+}
+
+// writeSheetRows writes every row of sheet to ss, rows are placed after the ones already written
+func (s *StreamGenerator) writeSheetRows(ss *streamSheet, sheet *xlsx.Sheet) error {
+	written := 0
+	err := sheet.ForEachRow(func(row *xlsx.Row) error {
+		rowNo := ss.rows + row.GetCoordinate()
+		var b strings.Builder
+		fmt.Fprintf(&b, `<row r="%d">`, rowNo+1)
+		err := row.ForEachCell(func(cell *xlsx.Cell) error {
+			col, _ := cell.GetCoordinates()
+			ref := xlsx.GetCellIDStringFromCoords(col, rowNo)
+			s.writeCell(&b, cell, ref)
+			if cell.Hyperlink != (xlsx.Hyperlink{}) {
+				ss.links = append(ss.links, streamLink{ref: ref, link: cell.Hyperlink})
+			}
+			return nil
+		}, xlsx.SkipEmptyCells)
+		if err != nil {
+			return err
+		}
+		b.WriteString("</row>")
+		written = row.GetCoordinate() + 1
+
+		_, err = ss.w.WriteString(b.String())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	ss.rows += written
+
+	return nil
+}
+
+// writeCell writes cell xml, strings are written inline
+func (s *StreamGenerator) writeCell(b *strings.Builder, cell *xlsx.Cell, ref string) {
+	fmt.Fprintf(b, `<c r="%s"`, ref)
+	if style := s.styles.cellStyle(cell); style > 0 {
+		fmt.Fprintf(b, ` s="%d"`, style)
+	}
+
+	switch {
+	case cell.Formula() != "":
+		if cell.Type() == xlsx.CellTypeStringFormula {
+			b.WriteString(` t="str"`)
+		}
+		b.WriteString("><f>" + escapeXML(strings.TrimPrefix(cell.Formula(), "=")) + "</f>")
+		if cell.Value != "" {
+			b.WriteString("<v>" + escapeXML(cell.Value) + "</v>")
+		}
+		b.WriteString("</c>")
+	case cell.Value == "":
+		b.WriteString("/>")
+	case cell.Type() == xlsx.CellTypeNumeric:
+		b.WriteString("><v>" + escapeXML(cell.Value) + "</v></c>")
+	case cell.Type() == xlsx.CellTypeBool:
+		b.WriteString(` t="b"><v>` + escapeXML(cell.Value) + "</v></c>")
+	case cell.Type() == xlsx.CellTypeError:
+		b.WriteString(` t="e"><v>` + escapeXML(cell.Value) + "</v></c>")
+	default:
+		b.WriteString(` t="inlineStr"><is><t xml:space="preserve">` + escapeXML(cell.Value) + "</t></is></c>")
+	}
+}
+
+// finishSheet writes end of ss with auto filter and data validations
+func (s *StreamGenerator) finishSheet(ss *streamSheet) error {
+	// Totals row is written below data, it's not covered by auto filter and conditional formatting
+	lastRow := ss.rows
+	sheet, err := s.g.GetSheet(ss.no)
+	if err != nil {
+		return err
+	}
+	if err := s.g.addTotalRow(ss.no, sheet, ss.rowLength, lastRow); err != nil {
+		return err
+	}
+	if err := s.writeSheetRows(ss, sheet); err != nil {
+		return err
+	}
+	if err := s.resetSheet(ss.no); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("</sheetData>")
+
+	s.g.setAutoFilter(ss.no, ss.meta, ss.rowLength, lastRow)
+	if ss.meta.AutoFilter != nil && lastRow > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="%s:%s"/>`, ss.meta.AutoFilter.TopLeftCell, ss.meta.AutoFilter.BottomRightCell)
+	}
+
+	formatting, err := conditionalFormattingXML(s.g.settings[ss.no].conditionalColumns, lastRow, s.g.dxfID(&s.styles.dxfs))
+	if err != nil {
+		return err
+	}
+	b.WriteString(formatting)
+
+	if len(ss.meta.DataValidations) > 0 {
+		fmt.Fprintf(&b, `<dataValidations count="%d">`, len(ss.meta.DataValidations))
+		enc := xml.NewEncoder(&b)
+		for _, dv := range ss.meta.DataValidations {
+			err := enc.EncodeElement(dv, xml.StartElement{Name: xml.Name{Local: "dataValidation"}})
+			if err != nil {
+				return err
+			}
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		b.WriteString("</dataValidations>")
+	}
+
+	var rels strings.Builder
+	relID := 0
+	if len(ss.links) > 0 {
+		b.WriteString("<hyperlinks>")
+		for _, link := range ss.links {
+			fmt.Fprintf(&b, `<hyperlink ref="%s"`, link.ref)
+			if link.link.Link != "" {
+				relID++
+				fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/hyperlink" Target="%s" TargetMode="External"/>`,
+					relID, relationshipsNS, escapeXML(link.link.Link))
+				fmt.Fprintf(&b, ` r:id="rId%d"`, relID)
+			}
+			if link.link.Location != "" {
+				fmt.Fprintf(&b, ` location="%s"`, escapeXML(link.link.Location))
+			}
+			if link.link.DisplayString != "" {
+				fmt.Fprintf(&b, ` display="%s"`, escapeXML(link.link.DisplayString))
+			}
+			if link.link.Tooltip != "" {
+				fmt.Fprintf(&b, ` tooltip="%s"`, escapeXML(link.link.Tooltip))
+			}
+			b.WriteString("/>")
+		}
+		b.WriteString("</hyperlinks>")
+	}
+
+	comments := s.g.settings[ss.no].comments
+	if len(comments) > 0 {
+		b.WriteString(legacyDrawingElement)
+		rels.WriteString(commentsRelationships(ss.no))
+		ss.comments = true
+	}
+	b.WriteString("</worksheet>")
+
+	if _, err := ss.w.WriteString(b.String()); err != nil {
+		return err
+	}
+	ss.finished = true
+	ss.links = nil
+	if s.open == ss {
+		s.open = nil
+	}
+	if err := ss.w.Flush(); err != nil {
+		return err
+	}
+
+	if rels.Len() == 0 {
+		return nil
+	}
+
+	err = s.writePart(fmt.Sprintf(streamSheetRelsPartFmt, ss.no+1), xmlHeader+`<Relationships xmlns="`+packageRelationshipsNS+`">`+rels.String()+"</Relationships>")
+	if err != nil || len(comments) == 0 {
+		return err
+	}
+
+	// Comments of finished sheet are not needed anymore
+	s.g.settings[ss.no].comments = nil
+	if err := s.writePart(fmt.Sprintf(commentsPartFmt, ss.no+1), commentsXML(comments)); err != nil {
+		return err
+	}
+
+	drawing, shapeBlock := vmlDrawingXML(s.shapeBlock, comments)
+	s.shapeBlock = shapeBlock
+
+	return s.writePart(fmt.Sprintf(vmlDrawingPartFmt, ss.no+1), drawing)
+}
+
+// workbook returns xl/workbook.xml content
+func (s *StreamGenerator) workbook() string {
+	var sheets, names strings.Builder
+	for _, ss := range s.sheets {
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"`, escapeXML(ss.name), ss.no+1, ss.no+1)
+		if s.g.settings[ss.no].veryHidden {
+			sheets.WriteString(` state="veryHidden"`)
+		} else if ss.hidden {
+			sheets.WriteString(` state="hidden"`)
+		}
+		sheets.WriteString("/>")
+
+		if ss.meta.AutoFilter != nil && ss.rows > 0 {
+			fmt.Fprintf(&names, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">%s</definedName>`,
+				ss.no, escapeXML(fmt.Sprintf("'%s'!%s:%s", strings.ReplaceAll(ss.name, "'", "''"),
+					absoluteCellRef(ss.meta.AutoFilter.TopLeftCell), absoluteCellRef(ss.meta.AutoFilter.BottomRightCell))))
+		}
+	}
+
+	for _, name := range s.g.wb.DefinedNames {
+		fmt.Fprintf(&names, `<definedName name="%s">%s</definedName>`, escapeXML(name.Name), escapeXML(name.Data))
+	}
+
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	fmt.Fprintf(&b, `<workbook xmlns="%s" xmlns:r="%s">`, spreadsheetMLNS, relationshipsNS)
+	b.WriteString(`<bookViews><workbookView/></bookViews>`)
+	b.WriteString("<sheets>" + sheets.String() + "</sheets>")
+	if names.Len() > 0 {
+		b.WriteString("<definedNames>" + names.String() + "</definedNames>")
+	}
+	b.WriteString("</workbook>")
+
+	return b.String()
+}
+
+// workbookRels returns xl/_rels/workbook.xml.rels content
+func (s *StreamGenerator) workbookRels() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="` + packageRelationshipsNS + `">`)
+	for _, ss := range s.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s" Target="worksheets/sheet%d.xml"/>`, ss.no+1, worksheetRelType, ss.no+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(s.sheets)+1, relationshipsNS)
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s/theme" Target="theme/theme1.xml"/>`, len(s.sheets)+2, relationshipsNS)
+	b.WriteString("</Relationships>")
+
+	return b.String()
+}
+
+// contentTypes returns [Content_Types].xml content
+func (s *StreamGenerator) contentTypes() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	withComments := false
+	for _, ss := range s.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/%s" ContentType="%s"/>`, fmt.Sprintf(streamSheetPartFmt, ss.no+1), worksheetPartType)
+		if ss.comments {
+			b.WriteString(commentsContentTypeOverride(ss.no))
+			withComments = true
+		}
+	}
+	if withComments {
+		b.WriteString(vmlDrawingExtension)
+	}
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/theme/theme1.xml" ContentType="application/vnd.openxmlformats-officedocument.theme+xml"/>`)
+	b.WriteString(`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>`)
+	b.WriteString(`<Override PartName="/docProps/app.xml" ContentType="application/vnd.openxmlformats-officedocument.extended-properties+xml"/>`)
+	b.WriteString("</Types>")
+
+	return b.String()
+}
+
+// absoluteCellRef turns cell reference like B12 into $B$12
+func absoluteCellRef(ref string) string {
+	i := strings.IndexAny(ref, "0123456789")
+	if i < 0 {
+		return "$" + ref
+	}
+
+	return "$" + ref[:i] + "$" + ref[i:]
+}
+
+// escapeXML escapes s to be used as xml text or attribute value
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
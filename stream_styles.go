@@ -0,0 +1,222 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// firstCustomNumFmtID is the first number format id which is not reserved for built-in formats
+const firstCustomNumFmtID = 164
+
+// styleTable holds unique xml elements of a styles.xml section
+type styleTable struct {
+	items []string
+	ids   map[string]int
+}
+
+// add returns id of item, item is appended if it was not added before
+func (t *styleTable) add(item string) int {
+	if t.ids == nil {
+		t.ids = make(map[string]int)
+	}
+	if id, ok := t.ids[item]; ok {
+		return id
+	}
+	t.items = append(t.items, item)
+	t.ids[item] = len(t.items) - 1
+
+	return len(t.items) - 1
+}
+
+// streamStyles collects cell styles used by StreamGenerator, identical styles share one id
+type streamStyles struct {
+	numFmts styleTable
+	fonts   styleTable
+	fills   styleTable
+	borders styleTable
+	xfs     styleTable
+	dxfs    styleTable
+}
+
+func newStreamStyles() *streamStyles {
+	s := &streamStyles{}
+	s.fills.add(fillXML(*xlsx.DefaultFill()))
+	s.fills.add(`<fill><patternFill patternType="gray125"/></fill>`)
+	s.styleID(xlsx.NewStyle(), "")
+
+	return s
+}
+
+// cellStyle returns id of cell's style and number format
+func (s *streamStyles) cellStyle(cell *xlsx.Cell) int {
+	return s.styleID(cell.GetStyle(), cell.NumFmt)
+}
+
+// styleID returns id of style combined with number format
+func (s *streamStyles) styleID(style *xlsx.Style, numFmt string) int {
+	numFmtID := 0
+	if numFmt != "" && !strings.EqualFold(numFmt, "general") {
+		numFmtID = firstCustomNumFmtID + s.numFmts.add(numFmt)
+	}
+	fontID := s.fonts.add(fontXML(style.Font))
+	fillID := s.fills.add(fillXML(style.Fill))
+	borderID := s.borders.add(borderXML(style.Border))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<xf numFmtId="%d" fontId="%d" fillId="%d" borderId="%d" xfId="0"`, numFmtID, fontID, fillID, borderID)
+	if numFmtID > 0 {
+		b.WriteString(` applyNumberFormat="1"`)
+	}
+	if fontID > 0 || style.ApplyFont {
+		b.WriteString(` applyFont="1"`)
+	}
+	if fillID > 0 || style.ApplyFill {
+		b.WriteString(` applyFill="1"`)
+	}
+	if borderID > 0 || style.ApplyBorder {
+		b.WriteString(` applyBorder="1"`)
+	}
+	if style.ApplyAlignment {
+		b.WriteString(` applyAlignment="1">` + alignmentXML(style.Alignment) + "</xf>")
+	} else {
+		b.WriteString("/>")
+	}
+
+	return s.xfs.add(b.String())
+}
+
+// marshal returns styles.xml content
+func (s *streamStyles) marshal() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	fmt.Fprintf(&b, `<styleSheet xmlns="%s">`, spreadsheetMLNS)
+	if len(s.numFmts.items) > 0 {
+		fmt.Fprintf(&b, `<numFmts count="%d">`, len(s.numFmts.items))
+		for i, code := range s.numFmts.items {
+			fmt.Fprintf(&b, `<numFmt numFmtId="%d" formatCode="%s"/>`, firstCustomNumFmtID+i, escapeXML(code))
+		}
+		b.WriteString("</numFmts>")
+	}
+	writeStyleTable(&b, "fonts", s.fonts)
+	writeStyleTable(&b, "fills", s.fills)
+	writeStyleTable(&b, "borders", s.borders)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	writeStyleTable(&b, "cellXfs", s.xfs)
+	b.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`)
+	if len(s.dxfs.items) > 0 {
+		b.WriteString(dxfsXML(s.dxfs))
+	}
+	b.WriteString("</styleSheet>")
+
+	return b.String()
+}
+
+func writeStyleTable(b *strings.Builder, name string, t styleTable) {
+	fmt.Fprintf(b, `<%s count="%d">%s</%s>`, name, len(t.items), strings.Join(t.items, ""), name)
+}
+
+func fontXML(font xlsx.Font) string {
+	var b strings.Builder
+	b.WriteString("<font>")
+	if font.Bold {
+		b.WriteString("<b/>")
+	}
+	if font.Italic {
+		b.WriteString("<i/>")
+	}
+	if font.Strike {
+		b.WriteString("<strike/>")
+	}
+	if font.Underline {
+		b.WriteString("<u/>")
+	}
+	if font.Size > 0 {
+		fmt.Fprintf(&b, `<sz val="%g"/>`, font.Size)
+	}
+	if font.Color != "" {
+		fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(font.Color))
+	}
+	if font.Name != "" {
+		fmt.Fprintf(&b, `<name val="%s"/>`, escapeXML(font.Name))
+	}
+	if font.Family > 0 {
+		fmt.Fprintf(&b, `<family val="%d"/>`, font.Family)
+	}
+	if font.Charset > 0 {
+		fmt.Fprintf(&b, `<charset val="%d"/>`, font.Charset)
+	}
+	b.WriteString("</font>")
+
+	return b.String()
+}
+
+func fillXML(fill xlsx.Fill) string {
+	patternType := fill.PatternType
+	if patternType == "" {
+		patternType = "none"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<fill><patternFill patternType="%s">`, escapeXML(patternType))
+	if fill.FgColor != "" {
+		fmt.Fprintf(&b, `<fgColor rgb="%s"/>`, escapeXML(fill.FgColor))
+	}
+	if fill.BgColor != "" {
+		fmt.Fprintf(&b, `<bgColor rgb="%s"/>`, escapeXML(fill.BgColor))
+	}
+	b.WriteString("</patternFill></fill>")
+
+	return b.String()
+}
+
+func borderXML(border xlsx.Border) string {
+	var b strings.Builder
+	b.WriteString("<border>")
+	for _, line := range []struct{ name, style, color string }{
+		{"left", border.Left, border.LeftColor},
+		{"right", border.Right, border.RightColor},
+		{"top", border.Top, border.TopColor},
+		{"bottom", border.Bottom, border.BottomColor},
+	} {
+		if line.style == "" || line.style == "none" {
+			fmt.Fprintf(&b, "<%s/>", line.name)
+			continue
+		}
+		fmt.Fprintf(&b, `<%s style="%s">`, line.name, escapeXML(line.style))
+		if line.color != "" {
+			fmt.Fprintf(&b, `<color rgb="%s"/>`, escapeXML(line.color))
+		}
+		fmt.Fprintf(&b, "</%s>", line.name)
+	}
+	b.WriteString("<diagonal/></border>")
+
+	return b.String()
+}
+
+func alignmentXML(alignment xlsx.Alignment) string {
+	var b strings.Builder
+	b.WriteString("<alignment")
+	if alignment.Horizontal != "" {
+		fmt.Fprintf(&b, ` horizontal="%s"`, escapeXML(alignment.Horizontal))
+	}
+	if alignment.Vertical != "" {
+		fmt.Fprintf(&b, ` vertical="%s"`, escapeXML(alignment.Vertical))
+	}
+	if alignment.TextRotation != 0 {
+		fmt.Fprintf(&b, ` textRotation="%d"`, alignment.TextRotation)
+	}
+	if alignment.WrapText {
+		b.WriteString(` wrapText="1"`)
+	}
+	if alignment.Indent != 0 {
+		fmt.Fprintf(&b, ` indent="%d"`, alignment.Indent)
+	}
+	if alignment.ShrinkToFit {
+		b.WriteString(` shrinkToFit="1"`)
+	}
+	b.WriteString("/>")
+
+	return b.String()
+}
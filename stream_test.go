@@ -0,0 +1,87 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+func TestStreamGenerator(t *testing.T) {
+	buff := new(bytes.Buffer)
+	s := NewStreamGenerator(buff, GeneratorOptionAutoFilter{}, GeneratorOptionFreezeFirstRow{},
+		GeneratorOptionCustomDropdown(map[string][]string{"custom_dropdown": {"a", "b"}}))
+
+	dataSheet, err := s.AddSheet("data")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	lookupSheet, err := s.AddSheet("lookup")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+
+	batches := [][]SomeStruct{
+		{{ID: 1, Value: 1.5, CustomDropdown: "a"}, {ID: 2, Value: 2.5, CustomDropdown: "b"}},
+		{{ID: 3, Value: 3.5, CustomDropdown: "a"}},
+	}
+	for _, batch := range batches {
+		if err := s.WriteRows(dataSheet, batch); err != nil {
+			t.Fatalf("WriteRows got err= %v", err)
+		}
+	}
+	if err := s.WriteRows(lookupSheet, []WithNilStruct{{ID: 1, NillableString: &exampleString}}); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+
+	err = s.WriteRows(dataSheet, batches[0])
+	if !errors.As(err, new(*ErrSheetFinished)) {
+		t.Errorf("WriteRows to finished sheet got err= %v, want ErrSheetFinished", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	var gotData []SomeStruct
+	var gotLookup []WithNilStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{
+		"data":   &gotData,
+		"lookup": &gotLookup,
+	}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff(append(batches[0], batches[1]...), gotData); diff != "" {
+		t.Errorf("data sheet differs from expected (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff([]WithNilStruct{{ID: 1, NillableString: &exampleString}}, gotLookup); diff != "" {
+		t.Errorf("lookup sheet differs from expected (-want +got)\n%s", diff)
+	}
+
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	sheet := wb.Sheet["data"]
+	if diff := cmp.Diff(&xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "C4"}, sheet.AutoFilter); diff != "" {
+		t.Errorf("auto filter differs from expected (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(1, len(sheet.DataValidations)); diff != "" {
+		t.Errorf("data validations count differs from expected (-want +got)\n%s", diff)
+	}
+	if col := sheet.Cols.FindColByIndex(2); col == nil || col.Width == nil || *col.Width != 25 {
+		t.Errorf("value column width differs from expected, got %v", col)
+	}
+	cell, err := sheet.Cell(1, 1)
+	if err != nil {
+		t.Fatalf("Cell got err= %v", err)
+	}
+	if diff := cmp.Diff("0.000000000000", cell.NumFmt); diff != "" {
+		t.Errorf("value cell format differs from expected (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,262 @@
+package autoxlsx
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// Tag key prefixes selecting cells the style key is applied to
+const (
+	headerStylePrefix      = "header-"
+	cellStylePrefix        = "cell-"
+	conditionalStylePrefix = "cf-"
+	totalStylePrefix       = "total-"
+)
+
+// CellStyle holds style of header or data cells parsed from tag. Style references named style of StyleRegistry,
+// other properties override properties of the named style.
+type CellStyle struct {
+	Style       string
+	Fill        string
+	Bold        bool
+	Italic      bool
+	Underline   bool
+	FontSize    float64
+	FontColor   string
+	Border      string
+	BorderColor string
+	Align       string
+	VAlign      string
+	Wrap        bool
+	Indent      int
+}
+
+// parse sets style property of tag key, false is returned for keys which are not style keys
+func (s *CellStyle) parse(key, value string) (bool, error) {
+	var err error
+	switch key {
+	case "style":
+		s.Style = value
+	case "fill":
+		s.Fill = value
+	case "bold":
+		s.Bold = true
+	case "italic":
+		s.Italic = true
+	case "underline":
+		s.Underline = true
+	case "font-size":
+		s.FontSize, err = strconv.ParseFloat(value, 64)
+	case "font-color":
+		s.FontColor = value
+	case "border":
+		s.Border = value
+	case "border-color":
+		s.BorderColor = value
+	case "align":
+		s.Align = value
+	case "valign":
+		s.VAlign = value
+	case "wrap":
+		s.Wrap = true
+	case "indent":
+		s.Indent, err = strconv.Atoi(value)
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+// merge returns s with properties set in o overriding its own
+func (s CellStyle) merge(o CellStyle) CellStyle {
+	if o.Style != "" {
+		s.Style = o.Style
+	}
+	if o.Fill != "" {
+		s.Fill = o.Fill
+	}
+	s.Bold = s.Bold || o.Bold
+	s.Italic = s.Italic || o.Italic
+	s.Underline = s.Underline || o.Underline
+	if o.FontSize > 0 {
+		s.FontSize = o.FontSize
+	}
+	if o.FontColor != "" {
+		s.FontColor = o.FontColor
+	}
+	if o.Border != "" {
+		s.Border = o.Border
+	}
+	if o.BorderColor != "" {
+		s.BorderColor = o.BorderColor
+	}
+	if o.Align != "" {
+		s.Align = o.Align
+	}
+	if o.VAlign != "" {
+		s.VAlign = o.VAlign
+	}
+	s.Wrap = s.Wrap || o.Wrap
+	if o.Indent > 0 {
+		s.Indent = o.Indent
+	}
+
+	return s
+}
+
+// IsZero reports whether no style property is set
+func (s CellStyle) IsZero() bool {
+	return s == CellStyle{}
+}
+
+// NewStyle creates xlsx style with properties of s, nil is returned when no property is set.
+// Named style reference is ignored, use StyleRegistry.Style to resolve it.
+func (s CellStyle) NewStyle() *xlsx.Style {
+	s.Style = ""
+	if s.IsZero() {
+		return nil
+	}
+
+	style := xlsx.NewStyle()
+	if s.Fill != "" {
+		style.Fill.FgColor = s.Fill
+		style.Fill.PatternType = "solid"
+		style.ApplyFill = true
+	}
+
+	if s.Bold || s.Italic || s.Underline || s.FontSize > 0 || s.FontColor != "" {
+		style.Font.Bold = s.Bold
+		style.Font.Italic = s.Italic
+		style.Font.Underline = s.Underline
+		if s.FontSize > 0 {
+			style.Font.Size = s.FontSize
+		}
+		style.Font.Color = s.FontColor
+		style.ApplyFont = true
+	}
+
+	if s.Border != "" {
+		style.Border = *xlsx.NewBorder(s.Border, s.Border, s.Border, s.Border)
+		style.Border.LeftColor = s.BorderColor
+		style.Border.RightColor = s.BorderColor
+		style.Border.TopColor = s.BorderColor
+		style.Border.BottomColor = s.BorderColor
+		style.ApplyBorder = true
+	}
+
+	if s.Align != "" || s.VAlign != "" || s.Wrap || s.Indent > 0 {
+		if s.Align != "" {
+			style.Alignment.Horizontal = s.Align
+		}
+		if s.VAlign != "" {
+			style.Alignment.Vertical = s.VAlign
+		}
+		style.Alignment.WrapText = s.Wrap
+		style.Alignment.Indent = s.Indent
+		style.ApplyAlignment = true
+	}
+
+	return style
+}
+
+// parseStyleKey sets style property of prefixed tag key (header-, cell-, cf- or total-) on options
+func (co *CustomOptions) parseStyleKey(key, value string) (bool, error) {
+	if name, ok := strings.CutPrefix(key, headerStylePrefix); ok {
+		return co.HeaderStyle.parse(name, value)
+	}
+
+	if name, ok := strings.CutPrefix(key, cellStylePrefix); ok {
+		return co.CellStyle.parse(name, value)
+	}
+
+	if name, ok := strings.CutPrefix(key, conditionalStylePrefix); ok {
+		return co.ConditionalStyle.parse(name, value)
+	}
+
+	if name, ok := strings.CutPrefix(key, totalStylePrefix); ok {
+		return co.TotalStyle.parse(name, value)
+	}
+
+	return false, nil
+}
+
+// StyleRegistry holds named styles and interns xlsx styles, so cells with identical style share one *xlsx.Style
+type StyleRegistry struct {
+	sync.Mutex
+	named  map[string]CellStyle
+	styles map[CellStyle]*xlsx.Style
+}
+
+// NewStyleRegistry creates empty style registry
+func NewStyleRegistry() *StyleRegistry {
+	return &StyleRegistry{
+		named:  make(map[string]CellStyle),
+		styles: make(map[CellStyle]*xlsx.Style),
+	}
+}
+
+// Define adds named style which can be referenced from tags with style:name, header-style:name or cell-style:name
+func (r *StyleRegistry) Define(name string, style CellStyle) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.named[name] = style
+}
+
+// resolve returns s with properties of referenced named style
+func (r *StyleRegistry) resolve(s CellStyle) (CellStyle, error) {
+	if s.Style == "" {
+		return s, nil
+	}
+
+	r.Lock()
+	named, ok := r.named[s.Style]
+	r.Unlock()
+	if !ok {
+		return s, &ErrStyleNotFound{Name: s.Style}
+	}
+
+	s.Style = ""
+	named.Style = ""
+
+	return named.merge(s), nil
+}
+
+// Style returns shared xlsx style of s, nil is returned when s has no properties
+func (r *StyleRegistry) Style(s CellStyle) (*xlsx.Style, error) {
+	s, err := r.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.IsZero() {
+		return nil, nil
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	style, ok := r.styles[s]
+	if !ok {
+		style = s.NewStyle()
+		r.styles[s] = style
+	}
+
+	return style, nil
+}
+
+// styleOf returns style of s shared through registry r, a new style is created when r is nil
+func styleOf(r *StyleRegistry, s CellStyle) *xlsx.Style {
+	if r == nil {
+		return s.NewStyle()
+	}
+
+	// Named styles are validated while parsing tags
+	style, _ := r.Style(s)
+
+	return style
+}
@@ -2,7 +2,7 @@ package autoxlsx
 
 import (
 	"reflect"
-	"sort"
+	"strconv"
 
 	"github.com/tealeg/xlsx/v3"
 
@@ -23,7 +23,7 @@ func (g *Generator) AddTableDataCells(row *xlsx.Row, sheetNo int, t reflect.Type
 	var currentCount int
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		added, err := g.addTableDataCell(row, sheetNo, data, &field, count)
+		added, err := g.addTableDataCell(row, sheetNo, t, data, &field, count)
 		if err != nil {
 			return 0, err
 		}
@@ -35,27 +35,34 @@ func (g *Generator) AddTableDataCells(row *xlsx.Row, sheetNo int, t reflect.Type
 	return currentCount, nil
 }
 
-func (g *Generator) addTableDataCell(row *xlsx.Row, sheetNo int, data reflect.Value, field *reflect.StructField, currentCount int) (int, error) {
+func (g *Generator) addTableDataCell(row *xlsx.Row, sheetNo int, owner reflect.Type, data reflect.Value, field *reflect.StructField, currentCount int) (int, error) {
+	// Data may be invalid (nil embedded struct or missing map key), type is taken from field then
 	var fv reflect.Value
+	var ft reflect.Type
 	if field != nil {
-		fv = data.FieldByName(field.Name)
-	} else {
+		ft = field.Type
+		if data.IsValid() {
+			fv = data.FieldByIndex(field.Index)
+		}
+	} else if data.IsValid() {
 		fv = data
+		ft = data.Type()
 	}
 
-	kind := fv.Kind()
-	if kind == reflect.Pointer {
-		fv = fv.Elem()
-		kind = fv.Kind()
+	if ft != nil && ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+		if fv.IsValid() {
+			fv = fv.Elem()
+		}
 	}
 
-	if kind == reflect.Map {
-		return g.addMapTableCells(row, sheetNo, data, *field, currentCount)
+	if ft != nil && ft.Kind() == reflect.Map && field != nil {
+		return g.addMapTableCells(row, sheetNo, owner, data, *field, currentCount)
 	}
 
-	if kind == reflect.Struct {
-		if !helpers.IsCommonGoStruct(fv.Type()) {
-			return g.AddTableDataCells(row, sheetNo, fv.Type(), fv, currentCount)
+	if ft != nil && ft.Kind() == reflect.Struct {
+		if !isValueField(field, ft) {
+			return g.AddTableDataCells(row, sheetNo, ft, fv, currentCount)
 		}
 	}
 
@@ -64,49 +71,121 @@ func (g *Generator) addTableDataCell(row *xlsx.Row, sheetNo int, data reflect.Va
 		return 1, nil
 	}
 
-	cell := row.AddCell()
-	addValueToCell(fv, cell)
+	cell := g.layoutCell(row, sheetNo, currentCount)
+	switch {
+	case fieldOptions.Formula != "":
+		if err := g.setCellFormula(sheetNo, cell, fieldOptions.Formula); err != nil {
+			return 0, err
+		}
+	case fv.IsValid() && fv.Type() == formulaType:
+		if err := g.setCellFormula(sheetNo, cell, fv.String()); err != nil {
+			return 0, err
+		}
+	case isLinkValue(fv):
+		setLinkValue(cell, fv)
+	case isCommentedValue(fv):
+		if err := g.setCommentedValue(sheetNo, cell, fv); err != nil {
+			return 0, err
+		}
+	default:
+		if err := addValueToCell(fv, cell); err != nil {
+			return 0, err
+		}
+		if fieldOptions.Text && cell.Type() == xlsx.CellTypeNumeric {
+			cell.SetString(cell.Value)
+		}
+		setDropdownLabel(cell, fieldOptions, fv)
+		g.addCellLink(sheetNo, cell, fieldOptions, fv)
+	}
+
+	if g.settings[sheetNo].sanitize && !fieldOptions.NoSanitize && cell.Formula() == "" {
+		sanitizeCell(cell)
+	}
 
 	fieldOptions.ApplyToCell(cell)
+	if isLinkValue(fv) || (!fieldOptions.Link.IsZero() && fv.IsValid()) {
+		// Link cells look like links unless cell style says otherwise
+		if style := styleOf(fieldOptions.Styles, defaultLinkStyle.merge(fieldOptions.CellStyle)); style != nil {
+			cell.SetStyle(style)
+		}
+	}
 	return 1, nil
 }
 
-func (g *Generator) addMapTableCells(row *xlsx.Row, sheetNo int, data reflect.Value, field reflect.StructField, currentCount int) (int, error) {
-	fv := data.FieldByName(field.Name)
-	var added int
+// addMapTableCells adds cell for every key collected for the map field, missing keys give empty cells
+func (g *Generator) addMapTableCells(row *xlsx.Row, sheetNo int, owner reflect.Type, data reflect.Value, field reflect.StructField, currentCount int) (int, error) {
+	var fv reflect.Value
+	if data.IsValid() {
+		fv = reflect.Indirect(data.FieldByIndex(field.Index))
+	}
 
-	keys := fv.MapKeys()
-	// Sort the keys by name
-	sort.Slice(keys, func(i, j int) bool {
-		return keys[i].String() < keys[j].String()
-	})
+	var added int
+	for _, key := range g.mapKeys[sheetNo][helpers.MapField{Owner: owner, Name: field.Name}] {
+		var value reflect.Value
+		// Maps with other keys have no columns, checkMapKey rejects them when tagged
+		if fv.IsValid() && !fv.IsNil() && fv.Type().Key().Kind() == reflect.String {
+			value = fv.MapIndex(reflect.ValueOf(key).Convert(fv.Type().Key()))
+		}
 
-	for _, key := range keys {
-		value := fv.MapIndex(key)
-		nAdded, err := g.addTableDataCell(row, sheetNo, value, nil, currentCount+added)
+		nAdded, err := g.addTableDataCell(row, sheetNo, nil, value, nil, currentCount+added)
 		if err != nil {
 			return 0, err
 		}
+
 		added += nAdded
 	}
 
 	return added, nil
 }
 
-func addValueToCell(data reflect.Value, cell *xlsx.Cell) {
+// layoutCell returns cell of row in column of custom options with index i, cells are placed by layout
+// of headers as fields order can differ from columns order
+func (g *Generator) layoutCell(row *xlsx.Row, sheetNo int, i int) *xlsx.Cell {
+	layout := g.settings[sheetNo].layout
+	if i >= len(layout) || layout[i] < 0 {
+		return row.AddCell()
+	}
+
+	col := layout[i]
+	if col >= row.Sheet.MaxCol {
+		row.Sheet.MaxCol = col + 1
+	}
+
+	return row.GetCell(col)
+}
+
+func addValueToCell(data reflect.Value, cell *xlsx.Cell) error {
 	switch data.Kind() {
 	case reflect.Pointer:
 		if data.IsNil() {
 			cell.SetValue(nil)
-			return
+			return nil
 		}
 
-		addValueToCell(data.Elem(), cell)
-		return
+		return addValueToCell(data.Elem(), cell)
 	case reflect.Invalid:
 		cell.SetValue(nil)
-		return
+		return nil
+	}
+
+	if ok, err := marshalCellValue(data, cell); ok {
+		return err
+	}
+
+	// Excel keeps 15 significant digits, longer integers are written as text so no digit is lost
+	switch data.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := data.Int(); n > helpers.MaxExactInt || n < -helpers.MaxExactInt {
+			cell.SetString(strconv.FormatInt(n, 10))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := data.Uint(); n > helpers.MaxExactInt {
+			cell.SetString(strconv.FormatUint(n, 10))
+			return nil
+		}
 	}
 
 	cell.SetValue(data.Interface())
+	return nil
 }
@@ -0,0 +1,107 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type TextStruct struct {
+	ID     uint64 `xlsx:"id"`
+	Small  int64  `xlsx:"small"`
+	Min    int64  `xlsx:"min"`
+	Code   string `xlsx:"code,text"`
+	Number int    `xlsx:"number,text"`
+}
+
+type textCell struct {
+	Value   string
+	Numeric bool
+	Format  string
+}
+
+func TestGenerator_Text(t *testing.T) {
+	data := []TextStruct{{ID: math.MaxUint64, Small: 123456789012345, Min: math.MinInt64, Code: "00123", Number: 42}}
+	want := []textCell{
+		{Value: "18446744073709551615", Numeric: false, Format: "general"},
+		{Value: "123456789012345", Numeric: true, Format: "general"},
+		{Value: "-9223372036854775808", Numeric: false, Format: "general"},
+		{Value: "00123", Numeric: false, Format: "@"},
+		{Value: "42", Numeric: false, Format: "@"},
+	}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"generator": func(buff *bytes.Buffer) error {
+			return Marshal(sheetList.New(map[string]interface{}{"sheet": data}), buff)
+		},
+		"stream": func(buff *bytes.Buffer) error {
+			s := NewStreamGenerator(buff)
+			if _, err := s.AddSheet("sheet"); err != nil {
+				return err
+			}
+			if err := s.WriteRows(0, data); err != nil {
+				return err
+			}
+			return s.Close()
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			if err := write(buff); err != nil {
+				t.Fatalf("write got err= %v", err)
+			}
+			wb, err := xlsx.OpenBinary(buff.Bytes())
+			if err != nil {
+				t.Fatalf("OpenBinary got err= %v", err)
+			}
+
+			var got []textCell
+			for col := range want {
+				cell, err := wb.Sheet["sheet"].Cell(1, col)
+				if err != nil {
+					t.Fatalf("Cell got err= %v", err)
+				}
+				got = append(got, textCell{Value: cell.Value, Numeric: cell.Type() == xlsx.CellTypeNumeric, Format: cell.GetNumberFormat()})
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("text cells differ from expected (-want +got)\n%s", diff)
+			}
+
+			var read []TextStruct
+			if err := UnmarshalSheet(wb.Sheet["sheet"], &read); err != nil {
+				t.Fatalf("UnmarshalSheet got err= %v", err)
+			}
+			if diff := cmp.Diff(data, read); diff != "" {
+				t.Errorf("UnmarshalSheet differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTextValue(t *testing.T) {
+	tests := []struct {
+		name string
+		cell func(*xlsx.Cell)
+		want string
+	}{
+		{name: "exponent integer", cell: func(c *xlsx.Cell) { c.SetNumeric("1.2345678901234E+19") }, want: "12345678901234000000"},
+		{name: "exponent fraction", cell: func(c *xlsx.Cell) { c.SetNumeric("1.5E-3") }, want: "1.5E-3"},
+		{name: "plain number", cell: func(c *xlsx.Cell) { c.SetNumeric("123") }, want: "123"},
+		{name: "text", cell: func(c *xlsx.Cell) { c.SetString("1E+3") }, want: "1E+3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := &xlsx.Cell{}
+			tt.cell(cell)
+			if diff := cmp.Diff(tt.want, textValue(cell)); diff != "" {
+				t.Errorf("textValue differs from expected (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arturwwl/gointtoletters"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// subtotalFunctions maps total: tag values to SUBTOTAL function numbers, which skip rows hidden by filter
+var subtotalFunctions = map[string]int{
+	"avg":   101,
+	"count": 103,
+	"max":   104,
+	"min":   105,
+	"sum":   109,
+}
+
+// defaultTotalStyle is used by totals row without style
+var defaultTotalStyle = CellStyle{Bold: true}
+
+// totalColumn holds aggregate of a column in totals row
+type totalColumn struct {
+	col      int
+	function int
+	options  *CustomOptions
+}
+
+// addTotalColumn registers aggregate of column from total: tag
+func (g *Generator) addTotalColumn(sheetNo int, col int, fieldOptions *CustomOptions) error {
+	if fieldOptions.Total == "" {
+		return nil
+	}
+
+	function, ok := subtotalFunctions[fieldOptions.Total]
+	if !ok {
+		return &ErrUnknownTotal{Name: fieldOptions.Total}
+	}
+
+	settings := g.settings[sheetNo]
+	settings.totals = append(settings.totals, totalColumn{col: col, function: function, options: fieldOptions})
+
+	return nil
+}
+
+// addTotalRow adds to sheet totals row of columns with total: tag, aggregating data rows down to lastRow
+func (g *Generator) addTotalRow(sheetNo int, sheet *xlsx.Sheet, rowLength, lastRow int) error {
+	settings := g.settings[sheetNo]
+	if len(settings.totals) == 0 || lastRow < 2 {
+		return nil
+	}
+
+	rowStyle := settings.totalRow.Style
+	if rowStyle.IsZero() {
+		rowStyle = defaultTotalStyle
+	}
+	rowStyle, err := g.styles.resolve(rowStyle)
+	if err != nil {
+		return err
+	}
+
+	row := sheet.AddRow()
+	cells := make([]*xlsx.Cell, rowLength)
+	for i := range cells {
+		cells[i] = row.AddCell()
+		if style := styleOf(g.styles, rowStyle); style != nil {
+			cells[i].SetStyle(style)
+		}
+	}
+	if rowLength > 0 && settings.totalRow.Label != "" {
+		cells[0].SetString(settings.totalRow.Label)
+	}
+
+	for _, total := range settings.totals {
+		if total.col >= rowLength {
+			continue
+		}
+
+		letter := gointtoletters.IntToLetters(total.col + 1)
+		cell := cells[total.col]
+		cell.SetFormula(fmt.Sprintf("SUBTOTAL(%d,%s2:%s%d)", total.function, letter, letter, lastRow))
+		if total.options.Format != "" {
+			cell.SetFormat(total.options.Format)
+		}
+
+		columnStyle, err := g.styles.resolve(total.options.TotalStyle)
+		if err != nil {
+			return err
+		}
+		if style := styleOf(g.styles, rowStyle.merge(columnStyle)); style != nil {
+			cell.SetStyle(style)
+		}
+	}
+
+	return nil
+}
+
+// isTotalRow tells if row is the totals row added by addTotalRow, which is the last one and aggregates columns with SUBTOTAL
+func isTotalRow(row *xlsx.Row) bool {
+	if row.GetCoordinate() != row.Sheet.MaxRow-1 {
+		return false
+	}
+
+	var found bool
+	_ = row.ForEachCell(func(cell *xlsx.Cell) error {
+		found = found || strings.HasPrefix(cell.Formula(), "SUBTOTAL(")
+		return nil
+	}, xlsx.SkipEmptyCells)
+
+	return found
+}
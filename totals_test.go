@@ -0,0 +1,146 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type TotalStruct struct {
+	Name   string  `xlsx:"name"`
+	Amount float64 `xlsx:"amount,format:0.00,total:sum,total-font-color:FFFF0000"`
+	Count  int     `xlsx:"count,total:count"`
+}
+
+// lastRowCells returns formulas (or values) and styles of the last row of sheet
+func lastRowCells(t *testing.T, sheet *xlsx.Sheet) ([]string, []*xlsx.Style) {
+	t.Helper()
+
+	row, err := sheet.Row(sheet.MaxRow - 1)
+	if err != nil {
+		t.Fatalf("Row got err= %v", err)
+	}
+
+	var values []string
+	var styles []*xlsx.Style
+	err = row.ForEachCell(func(cell *xlsx.Cell) error {
+		value := cell.Formula()
+		if value == "" {
+			value = cell.Value
+		}
+		values = append(values, value)
+		styles = append(styles, cell.GetStyle())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachCell got err= %v", err)
+	}
+
+	return values, styles
+}
+
+func TestGenerator_TotalRow(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionAutoFilter{}, GeneratorOptionTotalRow{Label: "Total"})
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	err = generator.AddData(sheetNo, []TotalStruct{{Name: "a", Amount: 1.5, Count: 1}, {Name: "b", Amount: 2, Count: 2}})
+	if err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+
+	sheet, _ := generator.GetSheet(sheetNo)
+	values, styles := lastRowCells(t, sheet)
+	if diff := cmp.Diff([]string{"Total", "SUBTOTAL(109,B2:B3)", "SUBTOTAL(103,C2:C3)"}, values); diff != "" {
+		t.Errorf("totals row differs from expected (-want +got)\n%s", diff)
+	}
+	if !styles[0].Font.Bold || !styles[1].Font.Bold || styles[1].Font.Color != "FFFF0000" {
+		t.Errorf("totals row style got %+v, %+v, want bold with red amount", styles[0].Font, styles[1].Font)
+	}
+	if diff := cmp.Diff(&xlsx.AutoFilter{TopLeftCell: "A1", BottomRightCell: "C3"}, sheet.AutoFilter); diff != "" {
+		t.Errorf("auto filter differs from expected (-want +got)\n%s", diff)
+	}
+
+	sheetNo, err = generator.AddSheet("invalid")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	err = generator.AddData(sheetNo, []struct {
+		Value int `xlsx:"value,total:median"`
+	}{{Value: 1}})
+	if !errors.As(err, new(*ErrUnknownTotal)) {
+		t.Errorf("AddData got err= %v, want ErrUnknownTotal", err)
+	}
+}
+
+func TestStreamGenerator_TotalRow(t *testing.T) {
+	buff := new(bytes.Buffer)
+	s := NewStreamGenerator(buff)
+	sheetNo, err := s.AddSheet("sheet", SheetOptionTotalRow("Sum", CellStyle{}))
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	for _, batch := range [][]TotalStruct{{{Name: "a", Amount: 1}}, {{Name: "b", Amount: 2}, {Name: "c", Amount: 3}}} {
+		if err := s.WriteRows(sheetNo, batch); err != nil {
+			t.Fatalf("WriteRows got err= %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	wb, err := xlsx.OpenBinary(buff.Bytes())
+	if err != nil {
+		t.Fatalf("OpenBinary got err= %v", err)
+	}
+	values, _ := lastRowCells(t, wb.Sheet["sheet"])
+	if diff := cmp.Diff([]string{"Sum", "SUBTOTAL(109,B2:B4)", "SUBTOTAL(103,C2:C4)"}, values); diff != "" {
+		t.Errorf("totals row differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestUnmarshal_TotalRow(t *testing.T) {
+	data := []TotalStruct{{Name: "a", Amount: 1.5, Count: 1}, {Name: "b", Amount: 2, Count: 2}}
+
+	generator := NewGenerator(GeneratorOptionTotalRow{Label: "Total"})
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(sheetNo, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	generated := new(bytes.Buffer)
+	if err := generator.SaveTo(generated); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	streamed := new(bytes.Buffer)
+	s := NewStreamGenerator(streamed, GeneratorOptionTotalRow{Label: "Total"})
+	if sheetNo, err = s.AddSheet("sheet"); err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(sheetNo, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+
+	for name, buff := range map[string]*bytes.Buffer{"generator": generated, "stream": streamed} {
+		var got []TotalStruct
+		err := Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet": &got}))
+		if err != nil {
+			t.Fatalf("%s: Unmarshal got err= %v", name, err)
+		}
+		if diff := cmp.Diff(data, got); diff != "" {
+			t.Errorf("%s: Unmarshal return value differs from expected (-want +got)\n%s", name, diff)
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package autoxlsx
+
+import (
+	"database/sql"
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arturwwl/gointtoletters"
+	"github.com/tealeg/xlsx/v3"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	nullTimeType = reflect.TypeFor[sql.NullTime]()
+)
+
+// readField describes struct field which values are read from sheet columns
+type readField struct {
+	index   []int
+	options *CustomOptions
+	isMap   bool
+	labels  map[string]string // Values of labeled dropdown by label
+	columns []int
+	keys    []string
+}
+
+// Unmarshal reads xlsx workbook, every value in out list has to be a pointer to slice, which is filled
+// with rows of sheet with the same name as list key
+func Unmarshal(r io.ReaderAt, size int64, out *sheetList.List, options ...UnmarshalOption) error {
+	wb, err := xlsx.OpenReaderAt(r, size)
+	if err != nil {
+		return err
+	}
+
+	report := &ErrUnmarshal{}
+	data, keys := out.Get()
+	for _, sheetName := range keys {
+		sheet, ok := wb.Sheet[sheetName]
+		if !ok {
+			return &ErrSheetNotFound{}
+		}
+
+		err = UnmarshalSheet(sheet, data[sheetName], options...)
+		if err != nil {
+			if err = appendCellErrors(report, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(report.Cells) > 0 {
+		return report
+	}
+
+	return nil
+}
+
+// UnmarshalSheet reads sheet rows into out, which has to be a pointer to slice of tagged structs (or pointers to them).
+// First row of sheet is treated as headers row. Cells which cannot be converted do not stop reading,
+// every one of them is reported in returned *ErrUnmarshal and corresponding fields are left with zero values.
+// Blank rows and totals row added with GeneratorOptionTotalRow are skipped.
+func UnmarshalSheet(sheet *xlsx.Sheet, out interface{}, options ...UnmarshalOption) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Pointer || outValue.IsNil() || outValue.Elem().Kind() != reflect.Slice {
+		return &ErrExpectedSlicePointer{}
+	}
+	sliceValue := outValue.Elem()
+
+	elemType := sliceValue.Type().Elem()
+	itemType := elemType
+	if itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		return &ErrExpectedSlicePointer{}
+	}
+
+	fields, err := readFields(itemType, nil)
+	if err != nil {
+		return err
+	}
+	order := columnOrder(options)
+	slices.SortStableFunc(fields, func(a, b *readField) int {
+		return rankColumn(a.options.ColumnName, a.options, order).compare(rankColumn(b.options.ColumnName, b.options, order))
+	})
+	labeledValues := dropdownValues(options)
+	names := headerNames(options)
+	for _, field := range fields {
+		field.labels = labeledValues[field.options.ColumnName]
+		field.options.Header = names[field.options.ColumnName]
+	}
+
+	report := &ErrUnmarshal{}
+	var headersRead bool
+	err = sheet.ForEachRow(func(row *xlsx.Row) error {
+		if !headersRead {
+			headersRead = true
+			return assignColumns(fields, readHeaders(row))
+		}
+		if isBlankRow(row, fields) || isTotalRow(row) {
+			return nil
+		}
+
+		item := reflect.New(itemType)
+		report.Cells = append(report.Cells, readRow(row, item.Elem(), fields, sheet.File != nil && sheet.File.Date1904)...)
+
+		if elemType.Kind() != reflect.Pointer {
+			item = item.Elem()
+		}
+		sliceValue.Set(reflect.Append(sliceValue, item))
+
+		return nil
+	}, xlsx.SkipEmptyRows)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Cells) > 0 {
+		return report
+	}
+
+	return nil
+}
+
+// readFields lists fields of t in order of fields, UnmarshalSheet sorts them like AddTableHeaders lays out their columns
+func readFields(t reflect.Type, index []int) ([]*readField, error) {
+	var fields []*readField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct && !isValueField(&f, ft) {
+			nested, err := readFields(ft, fieldIndex)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		options, err := newCustomOptions(f.Tag.Get("xlsx"), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if options.Skip {
+			continue
+		}
+		if ft.Kind() == reflect.Map && ft.Key().Kind() != reflect.String {
+			return nil, &ErrInvalidMapKey{Field: f.Name, Key: ft.Key()}
+		}
+
+		fields = append(fields, &readField{
+			index:   fieldIndex,
+			options: options,
+			isMap:   ft.Kind() == reflect.Map,
+		})
+	}
+
+	return fields, nil
+}
+
+// readHeaders returns trimmed header names of row
+func readHeaders(row *xlsx.Row) []string {
+	var headers []string
+	_ = row.ForEachCell(func(cell *xlsx.Cell) error {
+		headers = append(headers, strings.TrimSpace(cell.String()))
+		return nil
+	})
+
+	return headers
+}
+
+// assignColumns matches fields with header columns. Fields are matched by column name first, then every
+// remaining column is assigned to the map field which is laid out right after the closest matched field on its left.
+// Columns left without field are reported when there are map fields, as they would be lost otherwise.
+func assignColumns(fields []*readField, headers []string) error {
+	claimed := make([]bool, len(headers))
+	for _, field := range fields {
+		if field.isMap {
+			continue
+		}
+		for col, header := range headers {
+			if !claimed[col] && header == field.options.header() {
+				field.columns = []int{col}
+				claimed[col] = true
+				break
+			}
+		}
+	}
+
+	anchors := make(map[*readField]int)
+	anchor := -1
+	for _, field := range fields {
+		if field.isMap {
+			anchors[field] = anchor
+			continue
+		}
+		if len(field.columns) > 0 {
+			anchor = field.columns[0]
+		}
+	}
+	if len(anchors) == 0 {
+		return nil
+	}
+
+	var unassigned []string
+	for col, header := range headers {
+		if claimed[col] || header == "" {
+			continue
+		}
+		var target *readField
+		for _, field := range fields {
+			if field.isMap && anchors[field] < col && (target == nil || anchors[field] > anchors[target]) {
+				target = field
+			}
+		}
+		if target == nil {
+			unassigned = append(unassigned, header)
+			continue
+		}
+		target.columns = append(target.columns, col)
+		target.keys = append(target.keys, header)
+		claimed[col] = true
+	}
+	if len(unassigned) > 0 {
+		return &ErrUnassignedColumns{Headers: unassigned}
+	}
+
+	return nil
+}
+
+// readRow sets fields of item with values of row cells, it returns every cell which could not be converted
+func readRow(row *xlsx.Row, item reflect.Value, fields []*readField, date1904 bool) []*ErrCellConversion {
+	var cellErrors []*ErrCellConversion
+	for _, field := range fields {
+		for i, col := range field.columns {
+			cell := row.GetCell(col)
+			if cell.Value == "" {
+				continue
+			}
+			if value, ok := field.labels[cell.Value]; ok {
+				// Labeled value is read from a copy, so the sheet is left unchanged
+				cell = &xlsx.Cell{Value: value}
+			}
+
+			fv := fieldByIndex(item, field.index)
+			if !field.isMap {
+				err := setCellValue(cell, fv, date1904)
+				if err != nil {
+					cellErrors = append(cellErrors, newCellConversionError(row, cell, col, field.options.header(), fv.Type(), err))
+				}
+				continue
+			}
+
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			value := reflect.New(fv.Type().Elem()).Elem()
+			err := setCellValue(cell, value, date1904)
+			if err != nil {
+				cellErrors = append(cellErrors, newCellConversionError(row, cell, col, field.keys[i], value.Type(), err))
+				continue
+			}
+			fv.SetMapIndex(reflect.ValueOf(field.keys[i]).Convert(fv.Type().Key()), value)
+		}
+	}
+
+	return cellErrors
+}
+
+// isBlankRow tells if every cell of row in columns of fields is blank, like rows of template left unfilled
+func isBlankRow(row *xlsx.Row, fields []*readField) bool {
+	for _, field := range fields {
+		for _, col := range field.columns {
+			if strings.TrimSpace(row.GetCell(col).Value) != "" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// newCellConversionError describes cell of row from col column which could not be converted to t
+func newCellConversionError(row *xlsx.Row, cell *xlsx.Cell, col int, header string, t reflect.Type, err error) *ErrCellConversion {
+	return &ErrCellConversion{
+		Sheet:  row.Sheet.Name,
+		Row:    row.GetCoordinate() + 1,
+		Column: gointtoletters.IntToLetters(col + 1),
+		Header: header,
+		Value:  cell.Value,
+		Type:   t,
+		Err:    err,
+	}
+}
+
+// fieldByIndex returns nested field of v, nil pointers to embedded structs are allocated on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// setCellValue converts cell value to type of v and sets it
+func setCellValue(cell *xlsx.Cell, v reflect.Value, date1904 bool) error {
+	if v.Kind() == reflect.Pointer {
+		value := reflect.New(v.Type().Elem())
+		err := setCellValue(cell, value.Elem(), date1904)
+		if err != nil {
+			return err
+		}
+		v.Set(value)
+		return nil
+	}
+
+	raw := strings.TrimSpace(cell.Value)
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := parseTime(raw, date1904)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if v.Type() == commentedType {
+		var commented Commented
+		if err := setCellValue(cell, reflect.ValueOf(&commented.Value).Elem(), date1904); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(commented))
+		return nil
+	}
+
+	if v.Type() == hyperlinkType {
+		link := Hyperlink{Text: cell.Value, URL: cell.Hyperlink.Link}
+		if cell.Hyperlink.Location != "" {
+			link.URL = "#" + cell.Hyperlink.Location
+		}
+		v.Set(reflect.ValueOf(link))
+		return nil
+	}
+
+	if v.Type() == durationType {
+		days, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(time.Duration(days * float64(24*time.Hour)).Round(time.Millisecond)))
+		return nil
+	}
+
+	if v.CanAddr() {
+		if scanner, ok := v.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(scanSource(cell, v.Type(), raw, date1904))
+		}
+		if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(raw))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(textValue(cell))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			f, fErr := strconv.ParseFloat(raw, 64)
+			if fErr != nil || f != float64(int64(f)) || v.OverflowInt(int64(f)) {
+				return err
+			}
+			n = int64(f)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			f, fErr := strconv.ParseFloat(raw, 64)
+			if fErr != nil || f < 0 || f != float64(uint64(f)) || v.OverflowUint(uint64(f)) {
+				return err
+			}
+			n = uint64(f)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		if cell.Type() == xlsx.CellTypeNumeric {
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				v.Set(reflect.ValueOf(f))
+				return nil
+			}
+		}
+		v.Set(reflect.ValueOf(cell.Value))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// textValue returns value of cell read as text, integers stored as numbers are written without exponent,
+// e.g. 1.2345678901234E+19 of an identifier typed into not text cell. Apostrophe of sanitized strings is removed.
+func textValue(cell *xlsx.Cell) string {
+	if cell.Type() == xlsx.CellTypeString || cell.Type() == xlsx.CellTypeInline {
+		return unsanitize(cell.Value)
+	}
+	if cell.Type() != xlsx.CellTypeNumeric || !strings.ContainsAny(cell.Value, "eE") {
+		return cell.Value
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(cell.Value), 64)
+	if err != nil || f != math.Trunc(f) {
+		return cell.Value
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// scanSource returns cell value for Scan of sql.Scanner type t, times are parsed for sql.NullTime
+func scanSource(cell *xlsx.Cell, t reflect.Type, raw string, date1904 bool) interface{} {
+	if t == nullTimeType {
+		if tm, err := parseTime(raw, date1904); err == nil {
+			return tm
+		}
+	}
+	if cell.Type() == xlsx.CellTypeNumeric {
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	}
+
+	return raw
+}
+
+// parseTime parses excel date number or RFC3339 text
+func parseTime(raw string, date1904 bool) (time.Time, error) {
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return xlsx.TimeFromExcelTime(f, date1904), nil
+	}
+
+	return time.Parse(time.RFC3339, raw)
+}
@@ -0,0 +1,260 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/arturwwl/autoxlsx/sheetList"
+)
+
+type UnmarshalStruct struct {
+	ID       int                 `xlsx:"id"`
+	Values   map[string]*float64 `xlsx:"*"`
+	Name     *string             `xlsx:"name"`
+	Created  time.Time           `xlsx:"created,format:yyyy-mm-dd"`
+	Active   bool                `xlsx:"active"`
+	Internal string              `xlsx:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      map[string]interface{}
+		out     func() (map[string]interface{}, func() interface{})
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "flat struct with map",
+			in: map[string]interface{}{
+				"sheet1": []UnmarshalStruct{
+					{
+						ID:       1,
+						Values:   map[string]*float64{"a": &exampleFloat, "b": &exampleFloat},
+						Name:     &exampleString,
+						Created:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+						Active:   true,
+						Internal: "ignored",
+					},
+					{
+						ID:      2,
+						Values:  map[string]*float64{"a": &exampleFloat, "b": nil},
+						Created: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			out: func() (map[string]interface{}, func() interface{}) {
+				var got []UnmarshalStruct
+				return map[string]interface{}{"sheet1": &got}, func() interface{} { return got }
+			},
+			want: []UnmarshalStruct{
+				{
+					ID:      1,
+					Values:  map[string]*float64{"a": &exampleFloat, "b": &exampleFloat},
+					Name:    &exampleString,
+					Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					Active:  true,
+				},
+				{
+					ID:      2,
+					Values:  map[string]*float64{"a": &exampleFloat},
+					Created: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "nested struct with pointers",
+			in: map[string]interface{}{
+				"sheet1": []WithNestedStruct2{
+					{
+						WithOmittedFieldStruct: &WithOmittedFieldStruct{
+							ID:             2,
+							OmittedCheck:   true,
+							NillableString: &exampleString,
+						},
+						WithNilStruct: WithNilStruct{
+							ID: 1,
+						},
+					},
+				},
+			},
+			out: func() (map[string]interface{}, func() interface{}) {
+				var got []*WithNestedStruct2
+				return map[string]interface{}{"sheet1": &got}, func() interface{} { return got }
+			},
+			want: []*WithNestedStruct2{
+				{
+					WithOmittedFieldStruct: &WithOmittedFieldStruct{
+						ID:             2,
+						NillableString: &exampleString,
+					},
+					WithNilStruct: WithNilStruct{
+						ID: 1,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing sheet",
+			in: map[string]interface{}{
+				"sheet1": []WithNilStruct{{ID: 1}},
+			},
+			out: func() (map[string]interface{}, func() interface{}) {
+				var got []WithNilStruct
+				return map[string]interface{}{"sheet2": &got}, func() interface{} { return got }
+			},
+			wantErr: true,
+		},
+		{
+			name: "not a pointer",
+			in: map[string]interface{}{
+				"sheet1": []WithNilStruct{{ID: 1}},
+			},
+			out: func() (map[string]interface{}, func() interface{}) {
+				var got []WithNilStruct
+				return map[string]interface{}{"sheet1": got}, func() interface{} { return got }
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid value",
+			in: map[string]interface{}{
+				"sheet1": []WithNilStruct{{ID: 1, NillableString: &exampleString}},
+			},
+			out: func() (map[string]interface{}, func() interface{}) {
+				var got []struct {
+					ID     int `xlsx:"id"`
+					String int `xlsx:"string"`
+				}
+				return map[string]interface{}{"sheet1": &got}, func() interface{} { return got }
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			err := Marshal(sheetList.New(tt.in), buff)
+			if err != nil {
+				t.Fatalf("unable to prepare workbook, err= %v", err)
+			}
+
+			out, got := tt.out()
+			err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(out))
+
+			if (err == nil) == tt.wantErr {
+				t.Errorf("Unmarshal got err= %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tt.want, got()); diff != "" {
+					t.Errorf("Unmarshal return value differs from expected (-want +got)\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Template(t *testing.T) {
+	generator := NewGenerator(GeneratorOptionCustomDropdown(map[string][]string{"custom_dropdown": {"a", "b"}}))
+	sheetNo, err := generator.AddSheet("template")
+	if err != nil {
+		t.Fatalf("unable to prepare sheet, err= %v", err)
+	}
+	if err := AddTemplate[SomeStruct](generator, sheetNo, 10); err != nil {
+		t.Fatalf("AddTemplate got err= %v", err)
+	}
+
+	// Only the second template row is filled, the way a user would
+	sheet, _ := generator.GetSheet(sheetNo)
+	for col, value := range []string{"7", "1.5", "a"} {
+		cell, err := sheet.Cell(2, col)
+		if err != nil {
+			t.Fatalf("Cell got err= %v", err)
+		}
+		cell.Value = value
+	}
+	blank, _ := sheet.Cell(5, 0)
+	blank.Value = " "
+
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+
+	var got []SomeStruct
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"template": &got}))
+	if err != nil {
+		t.Fatalf("Unmarshal got err= %v", err)
+	}
+	if diff := cmp.Diff([]SomeStruct{{ID: 7, Value: 1.5, CustomDropdown: "a"}}, got); diff != "" {
+		t.Errorf("Unmarshal return value differs from expected (-want +got)\n%s", diff)
+	}
+}
+
+func TestUnmarshalSheet_ConversionReport(t *testing.T) {
+	type stringRow struct {
+		ID    string            `xlsx:"id"`
+		Value string            `xlsx:"value"`
+		Map   map[string]string `xlsx:"*"`
+	}
+	type typedRow struct {
+		ID    int                `xlsx:"id"`
+		Value *float64           `xlsx:"value"`
+		Map   map[string]float64 `xlsx:"*"`
+	}
+
+	buff := new(bytes.Buffer)
+	err := Marshal(sheetList.New(map[string]interface{}{
+		"sheet1": []stringRow{
+			{ID: "1", Value: "2.5", Map: map[string]string{"k": "x"}},
+			{ID: "two", Value: "2.5", Map: map[string]string{"k": "1"}},
+			{ID: "3", Value: "three", Map: map[string]string{"k": "1"}},
+		},
+	}), buff)
+	if err != nil {
+		t.Fatalf("unable to prepare workbook, err= %v", err)
+	}
+
+	var got []typedRow
+	err = Unmarshal(bytes.NewReader(buff.Bytes()), int64(buff.Len()), sheetList.New(map[string]interface{}{"sheet1": &got}))
+
+	var report *ErrUnmarshal
+	if !errors.As(err, &report) {
+		t.Fatalf("Unmarshal got err= %v, want *ErrUnmarshal", err)
+	}
+
+	type cellRef struct {
+		Row    int
+		Column string
+		Header string
+		Value  string
+		Type   string
+	}
+	var gotCells []cellRef
+	for _, cell := range report.Cells {
+		if cell.Sheet != "sheet1" {
+			t.Errorf("Unmarshal got sheet= %s, want sheet1", cell.Sheet)
+		}
+		gotCells = append(gotCells, cellRef{cell.Row, cell.Column, cell.Header, cell.Value, cell.Type.String()})
+	}
+	wantCells := []cellRef{
+		{Row: 2, Column: "C", Header: "k", Value: "x", Type: "float64"},
+		{Row: 3, Column: "A", Header: "id", Value: "two", Type: "int"},
+		{Row: 4, Column: "B", Header: "value", Value: "three", Type: "*float64"},
+	}
+	if diff := cmp.Diff(wantCells, gotCells); diff != "" {
+		t.Errorf("Unmarshal report differs from expected (-want +got)\n%s", diff)
+	}
+
+	if diff := cmp.Diff(3, len(got)); diff != "" {
+		t.Errorf("Unmarshal rows count differs from expected (-want +got)\n%s", diff)
+	}
+}
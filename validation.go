@@ -0,0 +1,173 @@
+package autoxlsx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// ValidationType holds type of data validation rule
+type ValidationType string
+
+// Supported data validation rule types
+const (
+	ValidationWhole      ValidationType = "whole"
+	ValidationDecimal    ValidationType = "decimal"
+	ValidationDate       ValidationType = "date"
+	ValidationTextLength ValidationType = "textLength"
+	ValidationCustom     ValidationType = "custom"
+)
+
+// validationTypes maps tag keys to types of range rules
+var validationTypes = map[string]ValidationType{
+	"valid-whole":   ValidationWhole,
+	"valid-decimal": ValidationDecimal,
+	"valid-date":    ValidationDate,
+	"valid-length":  ValidationTextLength,
+}
+
+// validationErrorStyles maps error-style: values to styles of error alert
+var validationErrorStyles = map[string]xlsx.DataValidationErrorStyle{
+	"stop":        xlsx.StyleStop,
+	"warning":     xlsx.StyleWarning,
+	"information": xlsx.StyleInformation,
+}
+
+// Validation holds data validation of data cells of a column. Rule is parsed from valid- tag keys,
+// prompt and error alert are used by dropdowns as well.
+type Validation struct {
+	Type        ValidationType
+	Min         string // Lower bound of range rule, empty for no bound
+	Max         string // Upper bound of range rule, empty for no bound
+	Formula     string // Formula of Custom rule, {cell} is replaced with the first data cell
+	Rows        int    // Number of validated rows, all rows of the sheet when 0
+	PromptTitle string
+	Prompt      string
+	ErrorTitle  string
+	Error       string
+	ErrorStyle  string // stop, warning or information
+}
+
+// parseValidation parses data validation tag key, false is returned for other keys
+func (v *Validation) parseValidation(key, value string) (bool, error) {
+	if t, ok := validationTypes[key]; ok {
+		v.Type = t
+		v.Min, v.Max, _ = strings.Cut(value, ":")
+		if v.Min == "" && v.Max == "" {
+			return true, &ErrInvalidValidation{Key: key, Value: value}
+		}
+		if t == ValidationDate {
+			var err error
+			if v.Min, err = dateFormula(v.Min); err != nil {
+				return true, err
+			}
+			if v.Max, err = dateFormula(v.Max); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	var err error
+	switch key {
+	case "valid-formula":
+		v.Type = ValidationCustom
+		v.Formula = value
+	case "valid-rows":
+		v.Rows, err = strconv.Atoi(value)
+	case "prompt":
+		v.Prompt = value
+	case "prompt-title":
+		v.PromptTitle = value
+	case "error":
+		v.Error = value
+	case "error-title":
+		v.ErrorTitle = value
+	case "error-style":
+		if _, ok := validationErrorStyles[value]; !ok {
+			err = &ErrInvalidValidation{Key: key, Value: value}
+		}
+		v.ErrorStyle = value
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+// dateFormula returns DATE formula of YYYY-MM-DD date, empty date gives empty formula
+func dateFormula(date string) (string, error) {
+	if date == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("DATE(%d,%d,%d)", t.Year(), t.Month(), t.Day()), nil
+}
+
+// dataValidationMessages is implemented by data validations of tealeg/xlsx, which type is not exported
+type dataValidationMessages interface {
+	SetInput(title, msg *string)
+	SetError(style xlsx.DataValidationErrorStyle, title, msg *string)
+}
+
+// setMessages sets prompt and error alert of dv, error alert is always shown for rules so invalid values are rejected
+func (v Validation) setMessages(dv dataValidationMessages) {
+	if v.PromptTitle != "" || v.Prompt != "" {
+		dv.SetInput(optionalString(v.PromptTitle), optionalString(v.Prompt))
+	}
+	if v.Type != "" || v.ErrorTitle != "" || v.Error != "" || v.ErrorStyle != "" {
+		style, ok := validationErrorStyles[v.ErrorStyle]
+		if !ok {
+			style = xlsx.StyleStop
+		}
+		dv.SetError(style, optionalString(v.ErrorTitle), optionalString(v.Error))
+	}
+}
+
+// addDataValidation adds rule to data cells of column col of sheet, nothing is added when no rule is set
+func (v Validation) addDataValidation(sheet *xlsx.Sheet, col int) {
+	if v.Type == "" {
+		return
+	}
+
+	rows := v.Rows
+	if rows <= 0 {
+		rows = xlsx.Excel2006MaxRowIndex
+	}
+	dv := xlsx.NewDataValidation(1, col, rows, col, true)
+	dv.Type = string(v.Type)
+
+	switch {
+	case v.Type == ValidationCustom:
+		firstCell := xlsx.GetCellIDStringFromCoords(col, 1)
+		dv.Formula1 = strings.TrimPrefix(strings.ReplaceAll(v.Formula, cellPlaceholder, firstCell), "=")
+	case v.Min != "" && v.Max != "":
+		dv.Operator = "between"
+		dv.Formula1, dv.Formula2 = v.Min, v.Max
+	case v.Min != "":
+		dv.Operator = "greaterThanOrEqual"
+		dv.Formula1 = v.Min
+	default:
+		dv.Operator = "lessThanOrEqual"
+		dv.Formula1 = v.Max
+	}
+
+	v.setMessages(dv)
+	sheet.AddDataValidation(dv)
+}
+
+// optionalString returns pointer to s, nil for empty s
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
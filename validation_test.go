@@ -0,0 +1,82 @@
+package autoxlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type ValidationStruct struct {
+	Quantity int     `xlsx:"quantity,valid-whole:1:100,error-title:Quantity,error:Between 1 and 100"`
+	Price    float64 `xlsx:"price,valid-decimal:0,valid-rows:10"`
+	Code     string  `xlsx:"code,valid-length::8,prompt-title:Code,prompt:Up to 8 characters,error-style:information"`
+	Date     string  `xlsx:"date,valid-date:2024-01-01:2024-12-31"`
+	Even     int     `xlsx:"even,valid-formula:=ISEVEN({cell})"`
+	Choice   string  `xlsx:"choice,dropdown:2,prompt:Pick one"`
+}
+
+func TestGenerator_Validation(t *testing.T) {
+	want := []string{
+		`<dataValidation allowBlank="true" showErrorMessage="true" errorStyle="stop" errorTitle="Quantity" operator="between" error="Between 1 and 100" type="whole" sqref="A2:A1048576"><formula1>1</formula1><formula2>100</formula2></dataValidation>`,
+		`<dataValidation allowBlank="true" showErrorMessage="true" errorStyle="stop" operator="greaterThanOrEqual" type="decimal" sqref="B2:B11"><formula1>0</formula1></dataValidation>`,
+		`<dataValidation allowBlank="true" showInputMessage="true" showErrorMessage="true" errorStyle="information" operator="lessThanOrEqual" promptTitle="Code" prompt="Up to 8 characters" type="textLength" sqref="C2:C1048576"><formula1>8</formula1></dataValidation>`,
+		`<dataValidation allowBlank="true" showErrorMessage="true" errorStyle="stop" operator="between" type="date" sqref="D2:D1048576"><formula1>DATE(2024,1,1)</formula1><formula2>DATE(2024,12,31)</formula2></dataValidation>`,
+		`<dataValidation allowBlank="true" showErrorMessage="true" errorStyle="stop" type="custom" sqref="E2:E1048576"><formula1>ISEVEN(E2)</formula1></dataValidation>`,
+		`<dataValidation allowBlank="true" showInputMessage="true" prompt="Pick one" type="list" sqref="F2:F4"><formula1>&#34;a,b&#34;</formula1></dataValidation>`,
+	}
+	data := []ValidationStruct{{Quantity: 1}}
+
+	generator := NewGenerator(GeneratorOptionCustomDropdown(map[string][]string{"choice": {"a", "b"}}))
+	sheetNo, err := generator.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := generator.AddData(sheetNo, data); err != nil {
+		t.Fatalf("AddData got err= %v", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := generator.SaveTo(buff); err != nil {
+		t.Fatalf("SaveTo got err= %v", err)
+	}
+	if diff := cmp.Diff(want, sheetValidations(t, buff.Bytes())); diff != "" {
+		t.Errorf("data validations differ from expected (-want +got)\n%s", diff)
+	}
+
+	buff = new(bytes.Buffer)
+	s := NewStreamGenerator(buff, GeneratorOptionCustomDropdown(map[string][]string{"choice": {"a", "b"}}))
+	sheetNo, err = s.AddSheet("sheet")
+	if err != nil {
+		t.Fatalf("AddSheet got err= %v", err)
+	}
+	if err := s.WriteRows(sheetNo, data); err != nil {
+		t.Fatalf("WriteRows got err= %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close got err= %v", err)
+	}
+	if diff := cmp.Diff(want, sheetValidations(t, buff.Bytes())); diff != "" {
+		t.Errorf("stream data validations differ from expected (-want +got)\n%s", diff)
+	}
+}
+
+// sheetValidations returns dataValidation elements of the first sheet of file
+func sheetValidations(t *testing.T, file []byte) []string {
+	t.Helper()
+
+	sheet := readPart(t, file, "xl/worksheets/sheet1.xml")
+	start, end := strings.Index(sheet, "<dataValidation "), strings.Index(sheet, "</dataValidations>")
+	if start < 0 || end < start {
+		t.Fatalf("sheet got %s, want data validations", sheet)
+	}
+
+	var got []string
+	for _, dv := range strings.SplitAfter(sheet[start:end], "</dataValidation>") {
+		if dv != "" {
+			got = append(got, dv)
+		}
+	}
+
+	return got
+}